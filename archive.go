@@ -0,0 +1,158 @@
+package git
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// archiveWriter writes repository files into a tar.gz or zip stream
+type archiveWriter struct {
+	gz  *gzip.Writer
+	tar *tar.Writer
+	zip *zip.Writer
+	mod time.Time
+}
+
+func newArchiveWriter(w io.Writer, format ArchiveFormat, modified time.Time) (*archiveWriter, error) {
+	switch format {
+	case ArchiveTarGz:
+		gz := gzip.NewWriter(w)
+		return &archiveWriter{gz: gz, tar: tar.NewWriter(gz), mod: modified}, nil
+	case ArchiveZip:
+		return &archiveWriter{zip: zip.NewWriter(w), mod: modified}, nil
+	}
+	return nil, fmt.Errorf("unsupported archive format '%s'", format)
+}
+
+// addFile writes a regular file, or a symlink pointing at content when mode has fs.ModeSymlink
+func (a *archiveWriter) addFile(name string, mode fs.FileMode, content []byte) error {
+	if a.tar != nil {
+		hdr := &tar.Header{Name: name, Mode: int64(mode.Perm()), Size: int64(len(content)), ModTime: a.mod, Typeflag: tar.TypeReg}
+		if mode&fs.ModeSymlink != 0 {
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, string(content), 0
+		}
+		if err := a.tar.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeSymlink {
+			return nil
+		}
+		_, err := a.tar.Write(content)
+		return err
+	}
+
+	hdr := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: a.mod}
+	hdr.SetMode(mode)
+	w, err := a.zip.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// addDir writes a directory entry; zip readers need them to recreate empty directories
+func (a *archiveWriter) addDir(name string) error {
+	name = path.Clean(name) + "/"
+	if a.tar != nil {
+		return a.tar.WriteHeader(&tar.Header{Name: name, Mode: 0o755, ModTime: a.mod, Typeflag: tar.TypeDir})
+	}
+	hdr := &zip.FileHeader{Name: name, Modified: a.mod}
+	hdr.SetMode(fs.ModeDir | 0o755)
+	_, err := a.zip.CreateHeader(hdr)
+	return err
+}
+
+func (a *archiveWriter) Close() error {
+	if a.zip != nil {
+		return a.zip.Close()
+	}
+	if err := a.tar.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
+
+// importArchive unpacks a tar or tar.gz stream and commits every regular file in a single commit.
+// Paths are committed as they appear in the archive; a leading "./" is dropped.
+func importArchive(ctx context.Context, a Adapter, logger *slog.Logger, projectID uuid.UUID, r io.Reader, message string, opts []Option) error {
+	logger.Info("ImportArchive", "projectID", projectID, "message", message)
+
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	progressFn := newCallOptions("", opts).progress
+	var progress Progress
+
+	var changes []FileChange
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			if hdr.Typeflag != tar.TypeDir {
+				return fmt.Errorf("failed to read archive: invalid path '%s'", hdr.Name)
+			}
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return fmt.Errorf("failed to read archive entry '%s': %w", name, err)
+			}
+			changes = append(changes, FileChange{Path: name, Bytes: content})
+			if progressFn != nil {
+				progress.Done++
+				progress.Path = name
+				progress.Bytes += int64(len(content))
+				progressFn(progress)
+			}
+		case tar.TypeDir:
+		default:
+			logger.Warn("Skipping unsupported archive entry", "path", name, "type", string(hdr.Typeflag))
+		}
+	}
+	if len(changes) == 0 {
+		return fmt.Errorf("failed to import archive: no files found")
+	}
+
+	if err := a.CommitFiles(ctx, projectID, changes, message, opts...); err != nil {
+		return err
+	}
+	if progressFn != nil {
+		progress.Total = progress.Done
+		progressFn(progress)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditSink receives one AuditRecord per mutating Adapter call, after the call returns.
+// Implementations must be safe for concurrent use and should not block for long.
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord)
+}
+
+// AuditFunc adapts a function to AuditSink
+type AuditFunc func(ctx context.Context, record AuditRecord)
+
+// Record calls f
+func (f AuditFunc) Record(ctx context.Context, record AuditRecord) {
+	f(ctx, record)
+}
+
+// NewJSONAuditSink writes each record as one line of JSON to w, e.g. a file tailed by a SIEM agent
+func NewJSONAuditSink(w io.Writer) AuditSink {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return AuditFunc(func(ctx context.Context, record AuditRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(record)
+	})
+}
+
+// auditAdapter wraps an Adapter, reporting every mutating call to an AuditSink
+type auditAdapter struct {
+	next Adapter
+	sink AuditSink
+}
+
+var _ Adapter = (*auditAdapter)(nil)
+
+// NewAuditAdapter wraps next so every call that writes to a repository is reported to sink:
+// who made it, on which repository, paths, commit SHA, message and outcome. Reads pass through.
+func NewAuditAdapter(next Adapter, sink AuditSink) Adapter {
+	return &auditAdapter{next: next, sink: sink}
+}
+
+// audit runs call with a hook capturing the commit SHA, then records its outcome
+func (a *auditAdapter) audit(ctx context.Context, operation string, projectID uuid.UUID, paths []string, message string, opts []Option, call func(opts []Option) error) error {
+	var sha string
+	start := time.Now()
+	err := call(append(slices.Clone(opts), withCommitHook(func(s string) { sha = s })))
+
+	o := newCallOptions("", opts)
+	record := AuditRecord{
+		Time:      start,
+		Operation: operation,
+		ProjectID: projectID,
+		Owner:     o.owner,
+		Branch:    o.branch,
+		Paths:     paths,
+		CommitSHA: sha,
+		Message:   o.message(message),
+		Sudo:      sudoUser(ctx),
+		Outcome:   outcome(err),
+		Duration:  time.Since(start),
+	}
+	if o.authorName != "" {
+		record.Author = fmt.Sprintf("%s <%s>", o.authorName, o.authorEmail)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	a.sink.Record(ctx, record)
+	return err
+}
+
+// changePaths lists the paths a set of changes touches, sources of renames included
+func changePaths(files []FileChange) []string {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.FromPath != "" {
+			paths = append(paths, f.FromPath)
+		}
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+// nodePaths lists the paths of files
+func nodePaths(files []FileNode) []string {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+func (a *auditAdapter) GetFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	return a.next.GetFile(ctx, projectID, path, opts...)
+}
+
+func (a *auditAdapter) GetFiles(ctx context.Context, projectID uuid.UUID, paths []string, opts ...Option) (map[string]*FileNode, error) {
+	return a.next.GetFiles(ctx, projectID, paths, opts...)
+}
+
+func (a *auditAdapter) StatFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	return a.next.StatFile(ctx, projectID, path, opts...)
+}
+
+func (a *auditAdapter) ListFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	return a.next.ListFiles(ctx, projectID, path, opts...)
+}
+
+func (a *auditAdapter) ListFilesRecursive(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	return a.next.ListFilesRecursive(ctx, projectID, path, opts...)
+}
+
+func (a *auditAdapter) IterateFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) iter.Seq2[FileNode, error] {
+	return a.next.IterateFiles(ctx, projectID, path, opts...)
+}
+
+func (a *auditAdapter) OpenFile(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) (io.ReadCloser, error) {
+	return a.next.OpenFile(ctx, projectID, path, ref, opts...)
+}
+
+func (a *auditAdapter) CommitFile(ctx context.Context, projectID uuid.UUID, path, content, message string, opts ...Option) error {
+	return a.audit(ctx, "CommitFile", projectID, []string{path}, message, opts, func(opts []Option) error {
+		return a.next.CommitFile(ctx, projectID, path, content, message, opts...)
+	})
+}
+
+func (a *auditAdapter) CommitFileBytes(ctx context.Context, projectID uuid.UUID, path string, content []byte, message string, opts ...Option) error {
+	return a.audit(ctx, "CommitFileBytes", projectID, []string{path}, message, opts, func(opts []Option) error {
+		return a.next.CommitFileBytes(ctx, projectID, path, content, message, opts...)
+	})
+}
+
+func (a *auditAdapter) WriteFile(ctx context.Context, projectID uuid.UUID, path string, r io.Reader, message string, opts ...Option) error {
+	return a.audit(ctx, "WriteFile", projectID, []string{path}, message, opts, func(opts []Option) error {
+		return a.next.WriteFile(ctx, projectID, path, r, message, opts...)
+	})
+}
+
+func (a *auditAdapter) CommitFiles(ctx context.Context, projectID uuid.UUID, files []FileChange, message string, opts ...Option) error {
+	return a.audit(ctx, "CommitFiles", projectID, changePaths(files), message, opts, func(opts []Option) error {
+		return a.next.CommitFiles(ctx, projectID, files, message, opts...)
+	})
+}
+
+func (a *auditAdapter) NewChangeSet(projectID uuid.UUID) *ChangeSet {
+	return newChangeSet(a, projectID)
+}
+
+func (a *auditAdapter) PlanChanges(ctx context.Context, projectID uuid.UUID, changes []FileChange, opts ...Option) (*Plan, error) {
+	return a.next.PlanChanges(ctx, projectID, changes, opts...)
+}
+
+func (a *auditAdapter) DeleteFile(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	return a.audit(ctx, "DeleteFile", projectID, []string{path}, message, opts, func(opts []Option) error {
+		return a.next.DeleteFile(ctx, projectID, path, message, opts...)
+	})
+}
+
+func (a *auditAdapter) DeletePath(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	return a.audit(ctx, "DeletePath", projectID, []string{path}, message, opts, func(opts []Option) error {
+		return a.next.DeletePath(ctx, projectID, path, message, opts...)
+	})
+}
+
+func (a *auditAdapter) SearchFiles(ctx context.Context, projectID uuid.UUID, query string, opts ...Option) ([]SearchMatch, error) {
+	return a.next.SearchFiles(ctx, projectID, query, opts...)
+}
+
+func (a *auditAdapter) MoveFile(ctx context.Context, projectID uuid.UUID, oldPath, newPath, message string, opts ...Option) error {
+	return a.audit(ctx, "MoveFile", projectID, []string{oldPath, newPath}, message, opts, func(opts []Option) error {
+		return a.next.MoveFile(ctx, projectID, oldPath, newPath, message, opts...)
+	})
+}
+
+func (a *auditAdapter) CopyFile(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, srcPath, dstPath, message string) error {
+	return a.audit(ctx, "CopyFile", dstProjectID, []string{dstPath}, message, nil, func([]Option) error {
+		return a.next.CopyFile(ctx, srcProjectID, dstProjectID, srcPath, dstPath, message)
+	})
+}
+
+func (a *auditAdapter) CopyFiles(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, paths map[string]string, message string) error {
+	return a.audit(ctx, "CopyFiles", dstProjectID, slices.Sorted(maps.Values(paths)), message, nil, func([]Option) error {
+		return a.next.CopyFiles(ctx, srcProjectID, dstProjectID, paths, message)
+	})
+}
+
+func (a *auditAdapter) ImportArchive(ctx context.Context, projectID uuid.UUID, r io.Reader, message string, opts ...Option) error {
+	return a.audit(ctx, "ImportArchive", projectID, nil, message, opts, func(opts []Option) error {
+		return a.next.ImportArchive(ctx, projectID, r, message, opts...)
+	})
+}
+
+func (a *auditAdapter) CreateRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) (string, error) {
+	var name string
+	err := a.audit(ctx, "CreateRepository", projectID, nil, "", opts, func(opts []Option) error {
+		var err error
+		name, err = a.next.CreateRepository(ctx, projectID, opts...)
+		return err
+	})
+	return name, err
+}
+
+func (a *auditAdapter) RepositoryExists(ctx context.Context, projectID uuid.UUID, opts ...Option) (bool, error) {
+	return a.next.RepositoryExists(ctx, projectID, opts...)
+}
+
+func (a *auditAdapter) ScaffoldProjectFiles(ctx context.Context, projectID uuid.UUID, files []FileNode) (*ScaffoldResult, error) {
+	return a.ScaffoldProjectFilesWithOptions(ctx, projectID, files, ScaffoldOptions{})
+}
+
+func (a *auditAdapter) ScaffoldProjectFilesWithOptions(ctx context.Context, projectID uuid.UUID, files []FileNode, opts ScaffoldOptions) (*ScaffoldResult, error) {
+	var result *ScaffoldResult
+	err := a.audit(ctx, "ScaffoldProjectFiles", projectID, nodePaths(files), "", nil, func([]Option) error {
+		var err error
+		result, err = a.next.ScaffoldProjectFilesWithOptions(ctx, projectID, files, opts)
+		return err
+	})
+	return result, err
+}
+
+func (a *auditAdapter) ScaffoldFromTemplates(ctx context.Context, projectID uuid.UUID, fsys fs.FS, data any) (*ScaffoldResult, error) {
+	var result *ScaffoldResult
+	err := a.audit(ctx, "ScaffoldFromTemplates", projectID, nil, "", nil, func([]Option) error {
+		var err error
+		result, err = a.next.ScaffoldFromTemplates(ctx, projectID, fsys, data)
+		return err
+	})
+	return result, err
+}
+
+func (a *auditAdapter) ScaffoldFromFS(ctx context.Context, projectID uuid.UUID, fsys fs.FS, root string) (*ScaffoldResult, error) {
+	var result *ScaffoldResult
+	err := a.audit(ctx, "ScaffoldFromFS", projectID, nil, "", nil, func([]Option) error {
+		var err error
+		result, err = a.next.ScaffoldFromFS(ctx, projectID, fsys, root)
+		return err
+	})
+	return result, err
+}
+
+func (a *auditAdapter) MergeScaffold(ctx context.Context, projectID uuid.UUID, baseFiles, newFiles []FileNode, strategy ScaffoldMergeStrategy) (*ScaffoldMergeResult, error) {
+	var result *ScaffoldMergeResult
+	err := a.audit(ctx, "MergeScaffold", projectID, nodePaths(newFiles), "", nil, func([]Option) error {
+		var err error
+		result, err = a.next.MergeScaffold(ctx, projectID, baseFiles, newFiles, strategy)
+		return err
+	})
+	return result, err
+}
+
+func (a *auditAdapter) Ping(ctx context.Context) error {
+	return a.next.Ping(ctx)
+}
+
+func (a *auditAdapter) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	return a.next.ServerInfo(ctx)
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// blameVersion is the content of a file as of commit
+type blameVersion struct {
+	commit  Commit
+	content string
+}
+
+// blameHistory attributes each line of the newest version to the commit that introduced it,
+// given the versions of a file from oldest to newest. Lines of the oldest version are
+// attributed to its commit, so a truncated history blames them on the oldest commit seen.
+func blameHistory(versions []blameVersion) []Commit {
+	var lines []Commit
+	prev := ""
+	for _, v := range versions {
+		var next []Commit
+		i := 0
+		for _, d := range diff.Do(prev, v.content) {
+			n := lineCount(d.Text)
+			switch d.Type {
+			case diffmatchpatch.DiffEqual:
+				next = append(next, lines[i:i+n]...)
+				i += n
+			case diffmatchpatch.DiffDelete:
+				i += n
+			case diffmatchpatch.DiffInsert:
+				for range n {
+					next = append(next, v.commit)
+				}
+			}
+		}
+		lines, prev = next, v.content
+	}
+	return lines
+}
+
+// blameRanges merges consecutive lines introduced by the same commit into ranges
+func blameRanges(lines []Commit) []BlameRange {
+	var ranges []BlameRange
+	for i, c := range lines {
+		if n := len(ranges); n > 0 && ranges[n-1].SHA == c.SHA {
+			ranges[n-1].EndLine = i + 1
+			continue
+		}
+		ranges = append(ranges, BlameRange{
+			StartLine:   i + 1,
+			EndLine:     i + 1,
+			SHA:         c.SHA,
+			AuthorName:  c.AuthorName,
+			AuthorEmail: c.AuthorEmail,
+			Timestamp:   c.Timestamp,
+		})
+	}
+	return ranges
+}
+
+// lineCount counts the lines in a line-mode diff chunk, including a final unterminated line
+func lineCount(text string) int {
+	n := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		n++
+	}
+	return n
+}
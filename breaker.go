@@ -0,0 +1,94 @@
+package git
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// breakerTransport fails requests fast with ErrUnavailable once threshold requests in a row have
+// failed, until cooldown has passed; then a single request probes whether the server is back
+type breakerTransport struct {
+	next      http.RoundTripper
+	logger    *slog.Logger
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int       // consecutive failures
+	openedAt time.Time // zero while closed
+	probing  bool      // a probe is in flight
+}
+
+func newBreakerTransport(next http.RoundTripper, env *GitConfig) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if env.BreakerThreshold < 1 {
+		return next
+	}
+	return &breakerTransport{
+		next:      next,
+		logger:    env.Logger,
+		threshold: env.BreakerThreshold,
+		cooldown:  env.BreakerCooldown,
+	}
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	probe, err := t.allow()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	t.record(req, resp, err, probe)
+	return resp, err
+}
+
+// allow admits a request while closed, and one probe at a time once the cooldown has passed.
+// It reports whether the admitted request is that probe.
+func (t *breakerTransport) allow() (probe bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.openedAt.IsZero() {
+		return false, nil
+	}
+	if t.probing || time.Since(t.openedAt) < t.cooldown {
+		return false, fmt.Errorf("%w: circuit breaker open after %d consecutive failures", ErrUnavailable, t.failures)
+	}
+	t.probing = true
+	return true, nil
+}
+
+// record counts dropped connections and 5xx responses; anything else means the server is up.
+// Only the probe itself ends probing, not requests admitted before the breaker opened.
+func (t *breakerTransport) record(req *http.Request, resp *http.Response, err error, probe bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if probe {
+		t.probing = false
+	}
+	if err != nil && req.Context().Err() != nil {
+		// The caller gave up, which says nothing about the server; let another request probe
+		return
+	}
+	if err == nil && resp.StatusCode < 500 {
+		if !t.openedAt.IsZero() {
+			t.logger.Info("Circuit breaker closed", "host", req.URL.Host)
+		}
+		t.failures, t.openedAt = 0, time.Time{}
+		return
+	}
+
+	t.failures++
+	switch {
+	case probe:
+		t.openedAt = time.Now()
+	case t.openedAt.IsZero() && t.failures >= t.threshold:
+		t.openedAt = time.Now()
+		t.logger.Warn("Circuit breaker open", "host", req.URL.Host, "failures", t.failures, "cooldown", t.cooldown)
+	}
+}
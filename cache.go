@@ -0,0 +1,148 @@
+package git
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Cache stores serialized reads. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+}
+
+// lruCache is an in-memory Cache evicting the least recently used entry
+type lruCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// NewLRUCache returns an in-memory Cache holding at most maxEntries values
+func NewLRUCache(maxEntries int) Cache {
+	return &lruCache{
+		max:     max(maxEntries, 1),
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func (c *lruCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+func (c *lruCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// cachedFile serves GetFile from the cache, revalidating the entry with a conditional raw
+// request against its blob SHA. ok is false when the cache cannot answer and the caller
+// should fall back to a normal read.
+func (g *GiteaAdapter) cachedFile(ctx context.Context, owner string, projectID uuid.UUID, ref, filePath string) (node *FileNode, ok bool) {
+	key := fmt.Sprintf("file:%s/%s@%s:%s", owner, projectID, ref, filePath)
+	data, hit := g.env.Cache.Get(key)
+	if !hit || json.Unmarshal(data, &node) != nil || node.Content == nil {
+		return nil, false
+	}
+
+	resp, err := g.do(ctx, http.MethodGet,
+		fmt.Sprintf("/repos/%s/%s/raw/%s?ref=%s", owner, g.repoName(projectID), escapePath(filePath), url.QueryEscape(ref)),
+		nil, http.Header{"If-None-Match": {`"` + node.SHA + `"`}})
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return node, true
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		// Changed since cached: the response already carries the new content and blob SHA
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, false
+		}
+		node.SHA = strings.Trim(strings.TrimPrefix(resp.Header.Get("ETag"), "W/"), `"`)
+		node.setContent(data)
+		g.storeFile(owner, projectID, ref, filePath, node)
+		return node, true
+	}
+	return nil, false
+}
+
+// storeFile caches a file read by GetFile
+func (g *GiteaAdapter) storeFile(owner string, projectID uuid.UUID, ref, filePath string, node *FileNode) {
+	if data, err := json.Marshal(node); err == nil {
+		g.env.Cache.Set(fmt.Sprintf("file:%s/%s@%s:%s", owner, projectID, ref, filePath), data)
+	}
+}
+
+// cachedList runs load at most once per commit: the key carries the commit ref points to,
+// so any push invalidates it. Refs that are neither a branch nor a commit SHA skip the cache.
+func (g *GiteaAdapter) cachedList(ctx context.Context, kind, owner string, projectID uuid.UUID, ref, dir string, load func() ([]FileNode, error)) ([]FileNode, error) {
+	commit := ref
+	if !commitSHA.MatchString(ref) {
+		branch, _, err := g.sdk(ctx).GetRepoBranch(owner, g.repoName(projectID), ref)
+		if err != nil || branch.Commit == nil {
+			return load()
+		}
+		commit = branch.Commit.ID
+	}
+
+	key := fmt.Sprintf("%s:%s/%s@%s:%s", kind, owner, projectID, commit, path.Clean("/"+strings.Trim(dir, "/")))
+	if data, hit := g.env.Cache.Get(key); hit {
+		var nodes []FileNode
+		if json.Unmarshal(data, &nodes) == nil {
+			return nodes, nil
+		}
+	}
+
+	nodes, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(nodes); err == nil {
+		g.env.Cache.Set(key, data)
+	}
+	return nodes, nil
+}
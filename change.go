@@ -0,0 +1,135 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// resolveOperation validates a change against whether its source path currently exists,
+// turning an empty operation into create or update.
+func resolveOperation(change FileChange, exists bool) (FileOperation, error) {
+	if _, err := change.Mode.gitMode(); err != nil {
+		return "", fmt.Errorf("file '%s': %w", change.Path, err)
+	}
+	switch change.Operation {
+	case "":
+		if exists {
+			return FileOperationUpdate, nil
+		}
+		return FileOperationCreate, nil
+	case FileOperationCreate:
+		if exists {
+			return "", fmt.Errorf("file '%s' already exists: %w", change.Path, ErrConflict)
+		}
+	case FileOperationUpdate, FileOperationDelete:
+		if !exists {
+			return "", fmt.Errorf("file '%s' not found for %s: %w", sourcePath(change), change.Operation, ErrNotFound)
+		}
+	default:
+		return "", fmt.Errorf("unknown file operation '%s' for '%s'", change.Operation, change.Path)
+	}
+	return change.Operation, nil
+}
+
+// checkExpectedSHA enforces WithExpectedSHA against the current blob SHA of path, "" when missing
+func checkExpectedSHA(path, expected, current string) error {
+	if expected == "" || expected == current {
+		return nil
+	}
+	if current == "" {
+		return fmt.Errorf("file '%s' was deleted since it was read: %w", path, ErrConflict)
+	}
+	return fmt.Errorf("file '%s' changed since it was read (expected %s, found %s): %w", path, expected, current, ErrConflict)
+}
+
+// sourcePath is the path a change reads from: FromPath for renames, otherwise Path
+func sourcePath(change FileChange) string {
+	if change.FromPath != "" {
+		return change.FromPath
+	}
+	return change.Path
+}
+
+// moveFile renames a file in one commit by reading it and re-committing it with FromPath set
+func moveFile(ctx context.Context, a Adapter, logger *slog.Logger, projectID uuid.UUID, oldPath, newPath, message string, opts []Option) error {
+	logger.Info("MoveFile", "projectID", projectID, "from", oldPath, "to", newPath, "message", message)
+
+	file, err := a.GetFile(ctx, projectID, oldPath, opts...)
+	if err != nil {
+		return fmt.Errorf("file not found for move: %w", err)
+	}
+	return a.CommitFiles(ctx, projectID, []FileChange{{
+		Operation: FileOperationUpdate,
+		Path:      newPath,
+		FromPath:  oldPath,
+		Bytes:     file.Data(),
+		SHA:       file.SHA,
+		Mode:      file.Mode,
+	}}, message, opts...)
+}
+
+// deletePath removes a file, or every file below a directory, in a single commit
+func deletePath(ctx context.Context, a Adapter, logger *slog.Logger, projectID uuid.UUID, target, message string, opts []Option) error {
+	logger.Info("DeletePath", "projectID", projectID, "path", target, "message", message)
+
+	target = strings.Trim(target, "/")
+	if target == "" || target == "." {
+		return fmt.Errorf("refusing to delete the repository root")
+	}
+
+	node, err := a.StatFile(ctx, projectID, target, opts...)
+	if err != nil {
+		return fmt.Errorf("path not found for deletion: %w", err)
+	}
+	if node.Type != FileTypeDir {
+		return a.CommitFiles(ctx, projectID, []FileChange{{
+			Operation: FileOperationDelete,
+			Path:      node.Path,
+			SHA:       node.SHA,
+		}}, message, opts...)
+	}
+
+	nodes, err := a.ListFilesRecursive(ctx, projectID, target, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to list '%s' for deletion: %w", target, err)
+	}
+	var changes []FileChange
+	var collect func([]FileNode)
+	collect = func(nodes []FileNode) {
+		for _, n := range nodes {
+			if n.Type == FileTypeDir {
+				collect(n.Children)
+				continue
+			}
+			changes = append(changes, FileChange{Operation: FileOperationDelete, Path: n.Path, SHA: n.SHA})
+		}
+	}
+	collect(nodes)
+	if len(changes) == 0 {
+		return nil
+	}
+	return a.CommitFiles(ctx, projectID, changes, message, opts...)
+}
+
+// copyFiles reads srcPath -> dstPath pairs from one project and commits them to another in a single commit
+func copyFiles(ctx context.Context, a Adapter, logger *slog.Logger, srcProjectID, dstProjectID uuid.UUID, paths map[string]string, message string) error {
+	logger.Info("CopyFiles", "src", srcProjectID, "dst", dstProjectID, "files", len(paths), "message", message)
+
+	changes := make([]FileChange, 0, len(paths))
+	for srcPath, dstPath := range paths {
+		file, err := a.GetFile(ctx, srcProjectID, srcPath)
+		if err != nil {
+			return fmt.Errorf("failed to read copy source '%s': %w", srcPath, err)
+		}
+		changes = append(changes, FileChange{Path: dstPath, Bytes: file.Data(), Mode: file.Mode})
+	}
+	// Keep commit contents deterministic regardless of map order
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return a.CommitFiles(ctx, dstProjectID, changes, message)
+}
@@ -0,0 +1,130 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// newChangeSet starts an empty change set committed through a
+func newChangeSet(a Adapter, projectID uuid.UUID) *ChangeSet {
+	return &ChangeSet{adapter: a, projectID: projectID}
+}
+
+// Put stages creating or replacing path with content
+func (cs *ChangeSet) Put(path, content string) *ChangeSet {
+	return cs.stage(FileChange{Path: path, Content: content})
+}
+
+// PutBytes stages creating or replacing path with binary content
+func (cs *ChangeSet) PutBytes(path string, content []byte) *ChangeSet {
+	return cs.stage(FileChange{Path: path, Bytes: content})
+}
+
+// PutExecutable stages creating or replacing path with content, marked executable
+func (cs *ChangeSet) PutExecutable(path, content string) *ChangeSet {
+	return cs.stage(FileChange{Path: path, Content: content, Mode: FileModeExecutable})
+}
+
+// Delete stages removing the file at path
+func (cs *ChangeSet) Delete(path string) *ChangeSet {
+	return cs.stage(FileChange{Operation: FileOperationDelete, Path: path})
+}
+
+// Move stages renaming the file at from to to; its content is read when the set is committed
+func (cs *ChangeSet) Move(from, to string) *ChangeSet {
+	return cs.stage(FileChange{Operation: FileOperationUpdate, Path: to, FromPath: from})
+}
+
+// Len returns the number of staged changes
+func (cs *ChangeSet) Len() int {
+	return len(cs.changes)
+}
+
+// Changes returns a copy of the staged changes in the order they were staged
+func (cs *ChangeSet) Changes() []FileChange {
+	return slices.Clone(cs.changes)
+}
+
+// Validate reports every problem with the staged changes without calling the backend:
+// invalid paths, a path touched by more than one change, and an empty set
+func (cs *ChangeSet) Validate() error {
+	errs := slices.Clone(cs.errs)
+	if len(cs.changes) == 0 && len(errs) == 0 {
+		errs = append(errs, errors.New("change set is empty"))
+	}
+
+	touched := map[string]bool{}
+	for _, c := range cs.changes {
+		for _, p := range []string{c.FromPath, c.Path} {
+			if p == "" {
+				continue
+			}
+			if touched[p] {
+				errs = append(errs, fmt.Errorf("path '%s' is staged more than once", p))
+			}
+			touched[p] = true
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Commit validates the change set and applies it as a single commit with CommitFiles.
+// Move sources are read first, in one GetFiles call; nothing is written if any is missing.
+func (cs *ChangeSet) Commit(ctx context.Context, message string, opts ...Option) error {
+	if err := cs.Validate(); err != nil {
+		return fmt.Errorf("invalid change set: %w", err)
+	}
+
+	changes := cs.Changes()
+	var sources []string
+	for _, c := range changes {
+		if c.FromPath != "" {
+			sources = append(sources, c.FromPath)
+		}
+	}
+	if len(sources) > 0 {
+		files, err := cs.adapter.GetFiles(ctx, cs.projectID, sources, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to read files to move: %w", err)
+		}
+		for i, c := range changes {
+			if file, ok := files[c.FromPath]; ok {
+				changes[i].Bytes = file.Data()
+				changes[i].SHA = file.SHA
+				changes[i].Mode = file.Mode
+			}
+		}
+	}
+	return cs.adapter.CommitFiles(ctx, cs.projectID, changes, message, opts...)
+}
+
+// stage normalizes the paths of c and records it, or the reason it is invalid
+func (cs *ChangeSet) stage(c FileChange) *ChangeSet {
+	var err error
+	if c.Path, err = changeSetPath(c.Path); err == nil && c.FromPath != "" {
+		c.FromPath, err = changeSetPath(c.FromPath)
+	}
+	if err != nil {
+		cs.errs = append(cs.errs, err)
+		return cs
+	}
+	cs.changes = append(cs.changes, c)
+	return cs
+}
+
+// changeSetPath trims a trailing slash from path and rejects empty and invalid paths, see validatePath
+func changeSetPath(path string) (string, error) {
+	if err := validatePath(path); err != nil {
+		return "", err
+	}
+	path = strings.TrimSuffix(path, "/")
+	if path == "" || path == "." {
+		return "", fmt.Errorf("empty path: %w", ErrInvalidPath)
+	}
+	return path, nil
+}
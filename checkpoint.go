@@ -0,0 +1,80 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var _ ScaffoldCheckpoint = (*FileCheckpoint)(nil)
+
+// NewFileCheckpoint stores checkpoints as <dir>/<projectID>.checkpoint, one "<sha> <path>" line
+// per committed file. Appending a line per file keeps a crash from losing earlier entries.
+func NewFileCheckpoint(dir string) (*FileCheckpoint, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	return &FileCheckpoint{dir: dir}, nil
+}
+
+func (c *FileCheckpoint) Load(ctx context.Context, projectID uuid.UUID) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.Open(c.file(projectID))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	done := map[string]string{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// A torn last line from a crash simply fails to parse and is committed again
+		if sha, path, ok := strings.Cut(sc.Text(), " "); ok && len(sha) == 40 {
+			done[path] = sha
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	return done, nil
+}
+
+func (c *FileCheckpoint) Save(ctx context.Context, projectID uuid.UUID, path, sha string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.file(projectID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%s %s\n", sha, path); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return f.Close()
+}
+
+func (c *FileCheckpoint) Clear(ctx context.Context, projectID uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Remove(c.file(projectID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (c *FileCheckpoint) file(projectID uuid.UUID) string {
+	return filepath.Join(c.dir, projectID.String()+".checkpoint")
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"cmp"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+)
+
+// setContent fills the content fields of f from data, keeping binary data in Bytes
+// so it survives JSON encoding
+func (f *FileNode) setContent(data []byte) {
+	content := string(data)
+	f.Content = &content
+	f.Size = int64(len(data))
+	f.IsBinary = isBinary(content)
+	f.LFS = parseLFSPointer(data)
+	sniff := data
+	if f.LFS != nil {
+		// Go by the name alone rather than the pointer text
+		sniff = nil
+	}
+	f.MimeType, f.Language = DetectFileType(cmp.Or(f.Path, f.Name), sniff)
+	f.Bytes = nil
+	if f.IsBinary {
+		f.Bytes = data
+	}
+}
+
+// Data returns the file content, preferring Bytes over Content
+func (f *FileNode) Data() []byte {
+	if f.Bytes != nil {
+		return f.Bytes
+	}
+	if f.Content != nil {
+		return []byte(*f.Content)
+	}
+	return nil
+}
+
+// data returns the content a change writes, preferring Bytes over Content
+func (c FileChange) data() []byte {
+	if c.Bytes != nil {
+		return c.Bytes
+	}
+	return []byte(c.Content)
+}
+
+// blobSHA computes the git blob object ID of content, matching what Gitea reports
+func blobSHA(content string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write([]byte(content))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// flattenFiles returns the non-directory nodes of a recursive listing in depth-first order
+func flattenFiles(nodes []FileNode) []FileNode {
+	var out []FileNode
+	for _, n := range nodes {
+		if n.Type == FileTypeDir {
+			out = append(out, flattenFiles(n.Children)...)
+		} else {
+			out = append(out, n)
+		}
+	}
+	return out
+}
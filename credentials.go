@@ -0,0 +1,160 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialProvider supplies the Gitea access token used for owner's repositories; requests not
+// tied to an owner, e.g. the server version, use the configured Owner. It is asked on every request,
+// so rotated tokens take effect immediately. Implementations must be safe for concurrent use.
+type CredentialProvider interface {
+	Token(ctx context.Context, owner string) (string, error)
+}
+
+// CredentialFunc adapts a function to CredentialProvider, e.g. to read tokens from a secret
+// store. It should cache tokens itself since it runs for every request.
+type CredentialFunc func(ctx context.Context, owner string) (string, error)
+
+// Token calls f
+func (f CredentialFunc) Token(ctx context.Context, owner string) (string, error) {
+	return f(ctx, owner)
+}
+
+// StaticCredentials always returns token
+func StaticCredentials(token string) CredentialProvider {
+	return CredentialFunc(func(context.Context, string) (string, error) {
+		return token, nil
+	})
+}
+
+// FileCredentials reads the token from path, e.g. a mounted Kubernetes secret, and reads it again
+// whenever the file changes. Surrounding whitespace is trimmed.
+func FileCredentials(path string) CredentialProvider {
+	return &fileCredentials{path: path}
+}
+
+// OwnerCredentials picks the provider registered for the owner, e.g. one token per organization,
+// and falls back to fallback for other owners
+func OwnerCredentials(byOwner map[string]CredentialProvider, fallback CredentialProvider) CredentialProvider {
+	return CredentialFunc(func(ctx context.Context, owner string) (string, error) {
+		if p, ok := byOwner[owner]; ok {
+			return p.Token(ctx, owner)
+		}
+		if fallback == nil {
+			return "", fmt.Errorf("no credentials for owner '%s': %w", owner, ErrUnauthorized)
+		}
+		return fallback.Token(ctx, owner)
+	})
+}
+
+// fileCredentials caches the token until the file's modification time or size changes
+type fileCredentials struct {
+	path    string
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+func (f *fileCredentials) Token(ctx context.Context, owner string) (string, error) {
+	// Stat follows the symlink Kubernetes swaps when it updates a secret
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.token != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.token, nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("failed to read token file: %s is empty", f.path)
+	}
+	f.token, f.modTime, f.size = token, info.ModTime(), info.Size()
+	return token, nil
+}
+
+// credentialTransport authenticates requests to the Gitea server with the token of the owner
+// they address. Requests that already carry an Authorization header, and requests to other
+// hosts such as LFS storage, are sent as they are.
+type credentialTransport struct {
+	next     http.RoundTripper
+	creds    CredentialProvider
+	host     string
+	basePath string
+	owner    string // used when the path names no owner
+}
+
+func newCredentialTransport(next http.RoundTripper, creds CredentialProvider, env *GitConfig) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	base, _ := url.Parse(env.BaseURL)
+	if base == nil {
+		base = &url.URL{}
+	}
+	return &credentialTransport{
+		next:     next,
+		creds:    creds,
+		host:     base.Host,
+		basePath: strings.TrimSuffix(base.Path, "/"),
+		owner:    env.Owner,
+	}
+}
+
+func (t *credentialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host || req.Header.Get("Authorization") != "" {
+		return t.next.RoundTrip(req)
+	}
+	owner := requestOwner(strings.TrimPrefix(req.URL.Path, t.basePath))
+	token, err := t.creds.Token(req.Context(), cmp.Or(owner, t.owner))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+	return t.next.RoundTrip(req)
+}
+
+// ownerlessRoutes are API routes whose second segment is an endpoint rather than an owner
+var ownerlessRoutes = map[string]bool{
+	"repos/search":  true,
+	"repos/migrate": true,
+	"repos/issues":  true, // repos/issues/search
+	"users/search":  true,
+}
+
+// requestOwner extracts the owner a Gitea URL path addresses: /api/v1/repos/{owner}/...,
+// /api/v1/orgs/{owner}/..., /api/v1/users/{owner}/... or /{owner}/{repo}.git/... for git and LFS.
+// Routes that address no owner, e.g. /api/v1/repos/search, yield "".
+func requestOwner(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) >= 4 && segments[0] == "api" && segments[1] == "v1" {
+		switch segments[2] {
+		case "repos", "orgs", "users":
+			if !ownerlessRoutes[segments[2]+"/"+segments[3]] {
+				return segments[3]
+			}
+		}
+		return ""
+	}
+	if len(segments) >= 2 && segments[0] != "api" {
+		return segments[0]
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// debugBodyLimit caps how much of each body an HTTPExchange captures
+const debugBodyLimit = 64 << 10
+
+// redacted replaces secrets in captured exchanges
+const redacted = "REDACTED"
+
+var (
+	// debugSecretHeaders never leave the process in clear text
+	debugSecretHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Gitea-Otp", "Proxy-Authorization"}
+	// debugSecretParams are query parameters Gitea accepts credentials in
+	debugSecretParams = []string{"token", "access_token", "sudo_token"}
+	// debugSecretFields matches JSON string fields holding credentials, e.g. webhook secrets
+	debugSecretFields = regexp.MustCompile(`("(?:secret|token|password|access_token|refresh_token|client_secret|authorization_header)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+// debugTransport hands every request/response pair to a hook while enabled
+type debugTransport struct {
+	next    http.RoundTripper
+	enabled *atomic.Bool
+	hook    func(HTTPExchange)
+}
+
+func newDebugTransport(next http.RoundTripper, enabled *atomic.Bool, env *GitConfig) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	hook := env.DebugHook
+	if hook == nil {
+		logger := env.Logger
+		hook = func(x HTTPExchange) { logExchange(logger, x) }
+	}
+	return &debugTransport{next: next, enabled: enabled, hook: hook}
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.enabled.Load() {
+		return t.next.RoundTrip(req)
+	}
+
+	x := HTTPExchange{
+		Method:        req.Method,
+		URL:           redactURL(req.URL),
+		RequestHeader: redactHeader(req.Header),
+	}
+	// Only rewindable bodies are captured; streamed uploads are sent untouched
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			x.RequestBody = readCapped(body)
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	x.Duration = time.Since(start)
+	if err != nil {
+		x.Err = err.Error()
+		t.hook(x)
+		return resp, err
+	}
+
+	x.Status = resp.StatusCode
+	x.ResponseHeader = redactHeader(resp.Header)
+	// Read the head of the body and hand the caller a body that replays it
+	head := make([]byte, debugBodyLimit)
+	n, _ := io.ReadFull(resp.Body, head)
+	x.ResponseBody = redactBody(head[:n])
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head[:n]), resp.Body), resp.Body}
+	t.hook(x)
+	return resp, nil
+}
+
+// SetHTTPDebug turns capturing of raw HTTP exchanges on or off at runtime, see GitConfig.DebugHTTP
+func (g *GiteaAdapter) SetHTTPDebug(enabled bool) {
+	g.debug.Store(enabled)
+}
+
+// logExchange is the default DebugHook
+func logExchange(logger *slog.Logger, x HTTPExchange) {
+	logger.Info("HTTP exchange",
+		"method", x.Method, "url", x.URL, "status", x.Status, "duration", x.Duration, "err", x.Err,
+		"requestHeader", x.RequestHeader, "requestBody", string(x.RequestBody),
+		"responseHeader", x.ResponseHeader, "responseBody", string(x.ResponseBody))
+}
+
+func readCapped(r io.Reader) []byte {
+	data, _ := io.ReadAll(io.LimitReader(r, debugBodyLimit))
+	return redactBody(data)
+}
+
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, key := range debugSecretHeaders {
+		if h.Get(key) != "" {
+			h.Set(key, redacted)
+		}
+	}
+	return h
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	c.User = nil
+	query := c.Query()
+	for _, key := range debugSecretParams {
+		if query.Has(key) {
+			query.Set(key, redacted)
+		}
+	}
+	c.RawQuery = query.Encode()
+	return c.String()
+}
+
+func redactBody(body []byte) []byte {
+	return debugSecretFields.ReplaceAll(body, []byte(`${1}"`+redacted+`"`))
+}
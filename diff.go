@@ -0,0 +1,142 @@
+package git
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// readAtRef reads the content of path as of ref
+type readAtRef func(ref, path string) (string, error)
+
+// buildDiff compares two path -> blob SHA indexes and renders a unified patch for every changed path
+func buildDiff(base, head string, baseIndex, headIndex map[string]string, read readAtRef) (*Diff, error) {
+	result := &Diff{Base: base, Head: head}
+	for _, fd := range compareIndexes(baseIndex, headIndex) {
+		var oldContent, newContent string
+		var err error
+		if fd.Status != DiffStatusAdded {
+			if oldContent, err = read(base, fd.Path); err != nil {
+				return nil, err
+			}
+		}
+		if fd.Status != DiffStatusDeleted {
+			if newContent, err = read(head, fd.Path); err != nil {
+				return nil, err
+			}
+		}
+
+		fd.IsBinary = isBinary(oldContent) || isBinary(newContent)
+		fd.Patch = unifiedPatch(fd, oldContent, newContent)
+		result.Files = append(result.Files, fd)
+	}
+	return result, nil
+}
+
+// compareIndexes lists the paths whose blob differs between two path -> blob SHA indexes, sorted, without patches
+func compareIndexes(baseIndex, headIndex map[string]string) []FileDiff {
+	paths := make([]string, 0, len(headIndex))
+	for p := range headIndex {
+		paths = append(paths, p)
+	}
+	for p := range baseIndex {
+		if _, ok := headIndex[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var files []FileDiff
+	for _, p := range paths {
+		oldSHA, inBase := baseIndex[p]
+		newSHA, inHead := headIndex[p]
+		if inBase && inHead && oldSHA == newSHA {
+			continue
+		}
+
+		fd := FileDiff{Path: p, OldSHA: oldSHA, NewSHA: newSHA}
+		switch {
+		case !inBase:
+			fd.Status = DiffStatusAdded
+		case !inHead:
+			fd.Status = DiffStatusDeleted
+		default:
+			fd.Status = DiffStatusModified
+		}
+		files = append(files, fd)
+	}
+	return files
+}
+
+// unifiedPatch renders a git-style unified diff for a single file
+func unifiedPatch(fd FileDiff, oldContent, newContent string) string {
+	fp := filePatch{binary: fd.IsBinary}
+	if fd.Status != DiffStatusAdded {
+		fp.from = patchFile{hash: plumbing.NewHash(fd.OldSHA), path: fd.Path}
+	}
+	if fd.Status != DiffStatusDeleted {
+		fp.to = patchFile{hash: plumbing.NewHash(fd.NewSHA), path: fd.Path}
+	}
+	if !fd.IsBinary {
+		for _, d := range diff.Do(oldContent, newContent) {
+			op := fdiff.Equal
+			switch d.Type {
+			case diffmatchpatch.DiffInsert:
+				op = fdiff.Add
+			case diffmatchpatch.DiffDelete:
+				op = fdiff.Delete
+			}
+			fp.chunks = append(fp.chunks, patchChunk{content: d.Text, op: op})
+		}
+	}
+
+	var buf strings.Builder
+	if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(patch{fp}); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// isBinary uses git's heuristic: a NUL byte in the first 8000 bytes
+func isBinary(content string) bool {
+	return bytes.IndexByte([]byte(content[:min(len(content), 8000)]), 0) >= 0
+}
+
+// patch, filePatch, patchFile and patchChunk adapt plain contents to go-git's diff encoder
+type patch []fdiff.FilePatch
+
+func (p patch) FilePatches() []fdiff.FilePatch { return p }
+func (p patch) Message() string                { return "" }
+
+type filePatch struct {
+	from, to fdiff.File
+	chunks   []fdiff.Chunk
+	binary   bool
+}
+
+func (p filePatch) IsBinary() bool               { return p.binary }
+func (p filePatch) Files() (from, to fdiff.File) { return p.from, p.to }
+func (p filePatch) Chunks() []fdiff.Chunk        { return p.chunks }
+
+type patchFile struct {
+	hash plumbing.Hash
+	path string
+}
+
+func (f patchFile) Hash() plumbing.Hash     { return f.hash }
+func (f patchFile) Mode() filemode.FileMode { return filemode.Regular }
+func (f patchFile) Path() string            { return f.path }
+
+type patchChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c patchChunk) Content() string       { return c.content }
+func (c patchChunk) Type() fdiff.Operation { return c.op }
@@ -0,0 +1,260 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+var _ Adapter = (*DryRunAdapter)(nil)
+
+// NewDryRunAdapter wraps next so reads go through while every mutating call is only logged and
+// recorded in Planned. Create or update is resolved against the current repository content.
+// A nil logger uses slog.Default().
+func NewDryRunAdapter(next Adapter, logger *slog.Logger) *DryRunAdapter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &DryRunAdapter{next: next, logger: logger}
+}
+
+// Planned returns the operations recorded so far, in call order
+func (d *DryRunAdapter) Planned() []PlannedOperation {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]PlannedOperation(nil), d.planned...)
+}
+
+// Reset clears the recorded operations
+func (d *DryRunAdapter) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.planned = nil
+}
+
+func (d *DryRunAdapter) GetFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	return d.next.GetFile(ctx, projectID, path, opts...)
+}
+
+func (d *DryRunAdapter) GetFiles(ctx context.Context, projectID uuid.UUID, paths []string, opts ...Option) (map[string]*FileNode, error) {
+	return d.next.GetFiles(ctx, projectID, paths, opts...)
+}
+
+func (d *DryRunAdapter) StatFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	return d.next.StatFile(ctx, projectID, path, opts...)
+}
+
+func (d *DryRunAdapter) ListFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	return d.next.ListFiles(ctx, projectID, path, opts...)
+}
+
+func (d *DryRunAdapter) ListFilesRecursive(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	return d.next.ListFilesRecursive(ctx, projectID, path, opts...)
+}
+
+func (d *DryRunAdapter) IterateFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) iter.Seq2[FileNode, error] {
+	return d.next.IterateFiles(ctx, projectID, path, opts...)
+}
+
+func (d *DryRunAdapter) OpenFile(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) (io.ReadCloser, error) {
+	return d.next.OpenFile(ctx, projectID, path, ref, opts...)
+}
+
+func (d *DryRunAdapter) Ping(ctx context.Context) error {
+	return d.next.Ping(ctx)
+}
+
+func (d *DryRunAdapter) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	return d.next.ServerInfo(ctx)
+}
+
+func (d *DryRunAdapter) RepositoryExists(ctx context.Context, projectID uuid.UUID, opts ...Option) (bool, error) {
+	return d.next.RepositoryExists(ctx, projectID, opts...)
+}
+
+func (d *DryRunAdapter) CommitFile(ctx context.Context, projectID uuid.UUID, path, content, message string, opts ...Option) error {
+	return d.planWrite(ctx, "CommitFile", projectID, path, message, opts)
+}
+
+func (d *DryRunAdapter) CommitFileBytes(ctx context.Context, projectID uuid.UUID, path string, content []byte, message string, opts ...Option) error {
+	return d.planWrite(ctx, "CommitFileBytes", projectID, path, message, opts)
+}
+
+func (d *DryRunAdapter) WriteFile(ctx context.Context, projectID uuid.UUID, path string, r io.Reader, message string, opts ...Option) error {
+	return d.planWrite(ctx, "WriteFile", projectID, path, message, opts)
+}
+
+func (d *DryRunAdapter) CommitFiles(ctx context.Context, projectID uuid.UUID, files []FileChange, message string, opts ...Option) error {
+	if err := validateChanges(files); err != nil {
+		return err
+	}
+	o := newCallOptions("", opts)
+	for _, f := range files {
+		operation := f.Operation
+		if operation == "" {
+			var err error
+			if operation, err = d.resolve(ctx, projectID, f.Path, "", opts); err != nil {
+				return err
+			}
+		}
+		d.record(PlannedOperation{
+			Method:    "CommitFiles",
+			ProjectID: projectID,
+			Operation: operation,
+			Path:      f.Path,
+			FromPath:  f.FromPath,
+			Branch:    o.branch,
+			Message:   o.message(message),
+		})
+	}
+	return nil
+}
+
+// NewChangeSet stages changes that are planned, not applied, when committed
+func (d *DryRunAdapter) NewChangeSet(projectID uuid.UUID) *ChangeSet {
+	return newChangeSet(d, projectID)
+}
+
+func (d *DryRunAdapter) PlanChanges(ctx context.Context, projectID uuid.UUID, changes []FileChange, opts ...Option) (*Plan, error) {
+	return d.next.PlanChanges(ctx, projectID, changes, opts...)
+}
+
+func (d *DryRunAdapter) DeleteFile(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	o := newCallOptions("", opts)
+	if _, err := d.next.StatFile(ctx, projectID, path, opts...); err != nil {
+		return err
+	}
+	d.record(PlannedOperation{
+		Method:    "DeleteFile",
+		ProjectID: projectID,
+		Operation: FileOperationDelete,
+		Path:      path,
+		Branch:    o.branch,
+		Message:   o.message(message),
+	})
+	return nil
+}
+
+// DeletePath records one delete per file below path
+func (d *DryRunAdapter) DeletePath(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	return deletePath(ctx, d, d.logger, projectID, path, message, opts)
+}
+
+func (d *DryRunAdapter) SearchFiles(ctx context.Context, projectID uuid.UUID, query string, opts ...Option) ([]SearchMatch, error) {
+	return d.next.SearchFiles(ctx, projectID, query, opts...)
+}
+
+// MoveFile records the rename as a single CommitFiles update
+func (d *DryRunAdapter) MoveFile(ctx context.Context, projectID uuid.UUID, oldPath, newPath, message string, opts ...Option) error {
+	return moveFile(ctx, d, d.logger, projectID, oldPath, newPath, message, opts)
+}
+
+func (d *DryRunAdapter) CopyFile(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, srcPath, dstPath, message string) error {
+	return copyFiles(ctx, d, d.logger, srcProjectID, dstProjectID, map[string]string{srcPath: dstPath}, message)
+}
+
+func (d *DryRunAdapter) CopyFiles(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, paths map[string]string, message string) error {
+	return copyFiles(ctx, d, d.logger, srcProjectID, dstProjectID, paths, message)
+}
+
+// ImportArchive reads the archive and records the files it would commit
+func (d *DryRunAdapter) ImportArchive(ctx context.Context, projectID uuid.UUID, r io.Reader, message string, opts ...Option) error {
+	return importArchive(ctx, d, d.logger, projectID, r, message, opts)
+}
+
+// CreateRepository records the creation and returns the bare project ID as the name, since
+// the owner is only known to the wrapped adapter
+func (d *DryRunAdapter) CreateRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) (string, error) {
+	o := newCallOptions("", opts)
+	exists, err := d.next.RepositoryExists(ctx, projectID, opts...)
+	if err != nil {
+		return "", err
+	}
+	if exists && !o.idempotent {
+		return "", fmt.Errorf("failed to create repository %s: %w", projectID, ErrConflict)
+	}
+	if !exists {
+		d.record(PlannedOperation{Method: "CreateRepository", ProjectID: projectID, Operation: FileOperationCreate})
+	}
+	return projectID.String(), nil
+}
+
+func (d *DryRunAdapter) ScaffoldProjectFiles(ctx context.Context, projectID uuid.UUID, files []FileNode) (*ScaffoldResult, error) {
+	return d.ScaffoldProjectFilesWithOptions(ctx, projectID, files, ScaffoldOptions{})
+}
+
+// ScaffoldProjectFilesWithOptions records one CommitFile per file; retries and throttling still apply
+func (d *DryRunAdapter) ScaffoldProjectFilesWithOptions(ctx context.Context, projectID uuid.UUID, files []FileNode, opts ScaffoldOptions) (*ScaffoldResult, error) {
+	return scaffold(ctx, d, d.logger, projectID, files, opts, Normalization{})
+}
+
+// ScaffoldFromTemplates renders fsys for real, so template errors surface, and records the commits
+func (d *DryRunAdapter) ScaffoldFromTemplates(ctx context.Context, projectID uuid.UUID, fsys fs.FS, data any) (*ScaffoldResult, error) {
+	files, err := renderTemplates(fsys, data)
+	if err != nil {
+		return nil, err
+	}
+	return d.ScaffoldProjectFiles(ctx, projectID, files)
+}
+
+func (d *DryRunAdapter) ScaffoldFromFS(ctx context.Context, projectID uuid.UUID, fsys fs.FS, root string) (*ScaffoldResult, error) {
+	files, err := subFiles(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	return d.ScaffoldProjectFiles(ctx, projectID, files)
+}
+
+// MergeScaffold reads the repository for real and records the merge commit
+func (d *DryRunAdapter) MergeScaffold(ctx context.Context, projectID uuid.UUID, baseFiles, newFiles []FileNode, strategy ScaffoldMergeStrategy) (*ScaffoldMergeResult, error) {
+	return mergeScaffold(ctx, d, d.logger, projectID, baseFiles, newFiles, strategy)
+}
+
+// planWrite records a single-file create or update
+func (d *DryRunAdapter) planWrite(ctx context.Context, method string, projectID uuid.UUID, path, message string, opts []Option) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	o := newCallOptions("", opts)
+	operation, err := d.resolve(ctx, projectID, path, o.expectedSHA, opts)
+	if err != nil {
+		return err
+	}
+	d.record(PlannedOperation{
+		Method:    method,
+		ProjectID: projectID,
+		Operation: operation,
+		Path:      path,
+		Branch:    o.branch,
+		Message:   o.message(message),
+	})
+	return nil
+}
+
+// resolve reports whether writing path would create or update it, failing like the write
+// would when the file no longer has the expected SHA
+func (d *DryRunAdapter) resolve(ctx context.Context, projectID uuid.UUID, path, expectedSHA string, opts []Option) (FileOperation, error) {
+	node, err := d.next.StatFile(ctx, projectID, path, opts...)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+	operation, current := FileOperationCreate, ""
+	if node != nil {
+		operation, current = FileOperationUpdate, node.SHA
+	}
+	return operation, checkExpectedSHA(path, expectedSHA, current)
+}
+
+func (d *DryRunAdapter) record(op PlannedOperation) {
+	d.logger.Info("DryRun", "method", op.Method, "projectID", op.ProjectID, "operation", op.Operation, "path", op.Path)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.planned = append(d.planned, op)
+}
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// fileType is the language and MIME type of a kind of source file
+type fileType struct {
+	language string
+	mimeType string
+}
+
+// fileTypes maps lowercase extensions to file types. Binary formats are left to content sniffing.
+var fileTypes = map[string]fileType{
+	".go":       {"Go", "text/x-go"},
+	".js":       {"JavaScript", "text/javascript"},
+	".mjs":      {"JavaScript", "text/javascript"},
+	".cjs":      {"JavaScript", "text/javascript"},
+	".jsx":      {"JavaScript", "text/jsx"},
+	".ts":       {"TypeScript", "text/typescript"},
+	".mts":      {"TypeScript", "text/typescript"},
+	".tsx":      {"TSX", "text/tsx"},
+	".py":       {"Python", "text/x-python"},
+	".rb":       {"Ruby", "text/x-ruby"},
+	".java":     {"Java", "text/x-java"},
+	".kt":       {"Kotlin", "text/x-kotlin"},
+	".kts":      {"Kotlin", "text/x-kotlin"},
+	".rs":       {"Rust", "text/x-rust"},
+	".c":        {"C", "text/x-c"},
+	".h":        {"C", "text/x-c"},
+	".cc":       {"C++", "text/x-c++"},
+	".cpp":      {"C++", "text/x-c++"},
+	".hpp":      {"C++", "text/x-c++"},
+	".cs":       {"C#", "text/x-csharp"},
+	".php":      {"PHP", "text/x-php"},
+	".swift":    {"Swift", "text/x-swift"},
+	".sh":       {"Shell", "text/x-shellscript"},
+	".bash":     {"Shell", "text/x-shellscript"},
+	".ps1":      {"PowerShell", "text/x-powershell"},
+	".sql":      {"SQL", "application/sql"},
+	".html":     {"HTML", "text/html"},
+	".htm":      {"HTML", "text/html"},
+	".css":      {"CSS", "text/css"},
+	".scss":     {"SCSS", "text/x-scss"},
+	".vue":      {"Vue", "text/x-vue"},
+	".svelte":   {"Svelte", "text/x-svelte"},
+	".json":     {"JSON", "application/json"},
+	".yaml":     {"YAML", "application/yaml"},
+	".yml":      {"YAML", "application/yaml"},
+	".toml":     {"TOML", "application/toml"},
+	".xml":      {"XML", "application/xml"},
+	".svg":      {"SVG", "image/svg+xml"},
+	".md":       {"Markdown", "text/markdown"},
+	".markdown": {"Markdown", "text/markdown"},
+	".proto":    {"Protocol Buffer", "text/x-protobuf"},
+	".graphql":  {"GraphQL", "application/graphql"},
+	".tf":       {"HCL", "text/x-hcl"},
+	".hcl":      {"HCL", "text/x-hcl"},
+	".csv":      {"CSV", "text/csv"},
+	".txt":      {"Text", "text/plain"},
+}
+
+// fileNameTypes maps file names without a telling extension, e.g. "Dockerfile.prod" by its prefix
+var fileNameTypes = map[string]fileType{
+	"dockerfile":     {"Dockerfile", "text/x-dockerfile"},
+	"makefile":       {"Makefile", "text/x-makefile"},
+	".gitignore":     {"Ignore List", "text/plain"},
+	".dockerignore":  {"Ignore List", "text/plain"},
+	".gitattributes": {"Git Attributes", "text/plain"},
+}
+
+// interpreterTypes maps shebang interpreters to the extension of their language
+var interpreterTypes = map[string]string{
+	"sh":      ".sh",
+	"bash":    ".sh",
+	"zsh":     ".sh",
+	"python":  ".py",
+	"python3": ".py",
+	"node":    ".js",
+	"ruby":    ".rb",
+}
+
+// DetectFileType returns the MIME type and language of the file at name, e.g. "text/x-go" and
+// "Go". It goes by extension or well-known file name, then by the shebang and sniffed content of
+// data, which may be nil. Binary content reports no language; unknown text is "text/plain; charset=utf-8".
+func DetectFileType(name string, data []byte) (mimeType, language string) {
+	if len(data) > 0 && isBinary(string(data)) {
+		return http.DetectContentType(data), ""
+	}
+
+	base := strings.ToLower(path.Base(name))
+	t, ok := fileTypes[path.Ext(base)]
+	if !ok {
+		t, ok = fileNameTypes[base]
+	}
+	if !ok {
+		t, ok = fileNameTypes[strings.TrimSuffix(base, path.Ext(base))]
+	}
+	if !ok {
+		t, ok = fileTypes[interpreterTypes[shebang(data)]]
+	}
+	if ok {
+		return t.mimeType, t.language
+	}
+	if len(data) > 0 {
+		return http.DetectContentType(data), ""
+	}
+	return "", ""
+}
+
+// shebang returns the interpreter a "#!" line names, e.g. "python3" for "#!/usr/bin/env python3"
+func shebang(data []byte) string {
+	if !bytes.HasPrefix(data, []byte("#!")) {
+		return ""
+	}
+	line, _, _ := bytes.Cut(data[2:], []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+	return interpreter
+}
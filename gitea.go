@@ -1,16 +1,34 @@
 package git
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"code.gitea.io/sdk/gitea"
 	"github.com/google/uuid"
 	"github.com/kelseyhightower/envconfig"
 )
 
+var _ Adapter = (*GiteaAdapter)(nil)
+
+// getFilesWorkers bounds the concurrent requests made by GetFiles
+const getFilesWorkers = 8
+
 func NewGiteaAdapter() (*GiteaAdapter, error) {
 	// Load configuration from the environment.
 	env := &GitConfig{}
@@ -18,88 +36,436 @@ func NewGiteaAdapter() (*GiteaAdapter, error) {
 		return nil, err
 	}
 
-	client, err := gitea.NewClient(
-		env.BaseURL, gitea.SetToken(env.Token))
+	httpClient, err := newHTTPClient(env)
+	if err != nil {
+		return nil, err
+	}
+	return newGiteaAdapter(env, httpClient)
+}
+
+// NewGiteaAdapterFromConfig builds the adapter from cfg without reading the environment.
+// Start from DefaultGitConfig to get the same defaults as the environment variables.
+func NewGiteaAdapterFromConfig(cfg *GitConfig) (*GiteaAdapter, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("invalid git config: BaseURL and one of Token, TokenFile, OAuth2 or Credentials are required")
+	}
+	env := *cfg
+
+	httpClient, err := newHTTPClient(&env)
+	if err != nil {
+		return nil, err
+	}
+	return newGiteaAdapter(&env, httpClient)
+}
+
+// DefaultGitConfig returns a GitConfig holding the documented defaults, leaving BaseURL and credentials empty
+func DefaultGitConfig() *GitConfig {
+	return &GitConfig{
+		IdName:           "ZamineBazi Orchestrator",
+		IdMail:           "bot@zaminebazi.com",
+		Owner:            "zaminebazi",
+		Branch:           "main",
+		CreateRepoInit:   true,
+		Timeout:          60 * time.Second,
+		RetryMax:         3,
+		RetryBackoff:     250 * time.Millisecond,
+		RetryMaxBackoff:  5 * time.Second,
+		RateLimit:        10,
+		RateBurst:        20,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// NewGiteaAdapterWithClient loads configuration from the environment but sends requests
+// through httpClient, ignoring the timeout, proxy and TLS settings of GitConfig.
+// Credentials, retries, rate limiting and the circuit breaker are still layered on top of its transport.
+func NewGiteaAdapterWithClient(httpClient *http.Client) (*GiteaAdapter, error) {
+	env := &GitConfig{}
+	if err := envconfig.Process("ORCHESTRATOR", env); err != nil {
+		return nil, err
+	}
+	return newGiteaAdapter(env, httpClient)
+}
+
+func newGiteaAdapter(env *GitConfig, base *http.Client) (*GiteaAdapter, error) {
+	creds := env.Credentials
+	switch {
+	case creds != nil:
+	case env.OAuth2.ClientID != "":
+		oauth := env.OAuth2
+		oauth.TokenURL = cmp.Or(oauth.TokenURL, strings.TrimSuffix(env.BaseURL, "/")+"/login/oauth/access_token")
+		creds = OAuth2Credentials(oauth, base)
+	case env.TokenFile != "":
+		creds = FileCredentials(env.TokenFile)
+	case env.Token != "":
+		creds = StaticCredentials(env.Token)
+	}
+	if env.BaseURL == "" || creds == nil {
+		return nil, fmt.Errorf("invalid git config: BaseURL and one of Token, TokenFile, OAuth2 or Credentials are required")
+	}
+	if env.Logger == nil {
+		env.Logger = slog.Default()
+	}
+	signer, err := loadSigner(env.SigningKey, env.SigningKeyPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	namer := env.RepoNamer
+	switch {
+	case namer != nil:
+	case env.RepoPrefix != "":
+		namer = PrefixRepoNamer(env.RepoPrefix)
+	default:
+		namer = UUIDRepoNamer()
+	}
+
+	// Copy so the caller's client is left untouched; each retry attempt takes its own limiter token
+	// and is captured for debugging, while the breaker sees one outcome per request
+	debug := new(atomic.Bool)
+	debug.Store(env.DebugHTTP)
+	httpClient := *base
+	httpClient.Transport = newDebugTransport(base.Transport, debug, env)
+	httpClient.Transport = newBreakerTransport(newRetryTransport(newRateLimitTransport(newCredentialTransport(httpClient.Transport, creds, env), env), env), env)
+
+	client, err := gitea.NewClient(env.BaseURL, gitea.SetHTTPClient(&httpClient))
 	if err != nil {
 		return nil, err
 	}
 
+	// Cache the server version so per-call clients skip the version request
+	version, _, err := client.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gitea.NewClient(env.BaseURL, gitea.SetGiteaVersion(version)); err != nil {
+		version = ""
+	}
+
 	return &GiteaAdapter{
-		client: client,
+		version: version,
+		http:    &httpClient,
+		logger:  env.Logger,
 		identity: &gitea.Identity{
 			Name:  env.IdName,
 			Email: env.IdMail,
 		},
-		env: env,
+		signer: signer,
+		creds:  creds,
+		namer:  namer,
+		debug:  debug,
+		env:    env,
+	}, nil
+}
+
+// Ping checks that Gitea is reachable and accepts the configured credentials, e.g. for a readiness probe
+func (g *GiteaAdapter) Ping(ctx context.Context) error {
+	g.logger.Debug("Ping")
+	if _, resp, err := g.sdk(ctx).GetMyUserInfo(); err != nil {
+		return fmt.Errorf("failed to reach Gitea: %w", giteaError(resp, err))
+	}
+	return nil
+}
+
+// ServerInfo reports the Gitea version, the authenticated user and the server's API limits
+func (g *GiteaAdapter) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	g.logger.Info("ServerInfo")
+	client := g.sdk(ctx)
+
+	version, resp, err := client.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", giteaError(resp, err))
+	}
+	user, resp, err := client.GetMyUserInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", giteaError(resp, err))
+	}
+	api, resp, err := client.GetGlobalAPISettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API settings: %w", giteaError(resp, err))
+	}
+	repo, resp, err := client.GetGlobalRepoSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository settings: %w", giteaError(resp, err))
+	}
+
+	return &ServerInfo{
+		Backend:         "gitea",
+		Version:         version,
+		User:            user.UserName,
+		MaxPageSize:     api.MaxResponseItems,
+		DefaultPageSize: api.DefaultPagingNum,
+		MaxBlobSize:     api.DefaultMaxBlobSize,
+		GitHTTP:         !repo.HTTPGitDisabled,
+		LFS:             !repo.LFSDisabled,
 	}, nil
 }
 
 // GetFileContent retrieves raw content of a file
-func (g *GiteaAdapter) GetFile(ctx context.Context, projectID uuid.UUID, path string) (*FileNode, error) {
-	log.Printf("GetFileContent projectID:%s, path:%s", projectID, path)
+func (g *GiteaAdapter) GetFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	g.logger.Info("GetFile", "projectID", projectID, "path", path)
+	o := g.callOptions(opts)
+
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
 
-	content, _, err := g.client.GetContents(g.env.Owner, projectID.String(), g.env.Branch, path)
+	if err := g.checkModified(ctx, o, projectID, path); err != nil {
+		return nil, err
+	}
+	if g.env.Cache != nil {
+		if node, ok := g.cachedFile(ctx, o.owner, projectID, o.branch, path); ok {
+			return g.resolveLFS(ctx, o, projectID, node)
+		}
+	}
+
+	node, err := g.getFile(ctx, o, projectID, path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get file contents: %w", err)
+		return nil, err
+	}
+	if g.env.Cache != nil {
+		g.storeFile(o.owner, projectID, o.branch, path, node)
 	}
+	return g.resolveLFS(ctx, o, projectID, node)
+}
 
-	decodedStr := content.Content
-	// Gitea returns content usually base64 encoded in 'Content' field if it's a file
-	if content.Encoding != nil && *content.Encoding == "base64" {
-		decodedBytes, err := base64.StdEncoding.DecodeString(*content.Content)
-		if err == nil {
-			tmp := string(decodedBytes)
-			decodedStr = &tmp
+// getFile reads path through the raw endpoint when it is at least RawFileThreshold bytes and
+// through the contents API otherwise
+func (g *GiteaAdapter) getFile(ctx context.Context, o callOptions, projectID uuid.UUID, path string) (*FileNode, error) {
+	if g.env.RawFileThreshold > 0 {
+		node, err := g.rawFile(ctx, o, projectID, path, g.env.RawFileThreshold)
+		if node != nil || err != nil {
+			return node, err
+		}
+	}
+
+	content, resp, err := g.sdk(ctx).GetContents(o.owner, g.repoName(projectID), o.branch, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file contents: %w", giteaError(resp, err))
+	}
+	// Gitea leaves out the content of files larger than its API blob size limit
+	if content.Type == "file" && content.Content == nil && content.Size > 0 {
+		node, err := g.rawFile(ctx, o, projectID, path, 0)
+		if node != nil || err != nil {
+			return node, err
 		}
 	}
 
-	return &FileNode{
+	node := &FileNode{
 		Name:    content.Name,
 		Path:    content.Path,
 		Type:    FileTypeFile,
 		SHA:     content.SHA,
 		Size:    content.Size,
-		Content: decodedStr,
-	}, nil
+		Content: content.Content,
+	}
+	// Gitea returns content usually base64 encoded in 'Content' field if it's a file
+	if content.Encoding != nil && *content.Encoding == "base64" {
+		if decoded, err := base64.StdEncoding.DecodeString(*content.Content); err == nil {
+			node.setContent(decoded)
+		}
+	}
+	return node, nil
+}
+
+// GetFiles fetches paths concurrently. Files that could be read are returned even when
+// others fail; the error joins the per-path failures.
+func (g *GiteaAdapter) GetFiles(ctx context.Context, projectID uuid.UUID, paths []string, opts ...Option) (map[string]*FileNode, error) {
+	g.logger.Info("GetFiles", "projectID", projectID, "files", len(paths))
+
+	if err := validatePaths(paths...); err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*FileNode, len(paths))
+	errs := make([]error, len(paths))
+	sem := make(chan struct{}, getFilesWorkers)
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			nodes[i], errs[i] = g.GetFile(ctx, projectID, path, opts...)
+		}()
+	}
+	wg.Wait()
+
+	files := make(map[string]*FileNode, len(paths))
+	var failed []error
+	for i, path := range paths {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Errorf("path %s: %w", path, errs[i]))
+			continue
+		}
+		files[path] = nodes[i]
+	}
+	return files, errors.Join(failed...)
+}
+
+// StatFile returns the type, SHA and size of a file or directory without its content,
+// read from the parent directory listing
+func (g *GiteaAdapter) StatFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	g.logger.Info("StatFile", "projectID", projectID, "path", path)
+	o := g.callOptions(opts)
+
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
+	return g.stat(ctx, o.owner, projectID, o.branch, path)
+}
+
+// stat finds path in the listing of its parent directory; the root comes from the commit at ref
+func (g *GiteaAdapter) stat(ctx context.Context, owner string, projectID uuid.UUID, ref, filePath string) (*FileNode, error) {
+	filePath = strings.Trim(filePath, "/")
+	if filePath == "" {
+		return g.statRoot(ctx, owner, projectID, ref)
+	}
+	dir := ""
+	if i := strings.LastIndex(filePath, "/"); i >= 0 {
+		dir = filePath[:i]
+	}
+
+	entries, err := g.listContents(ctx, owner, projectID, ref, dir, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Path == filePath {
+			return &entry, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to stat '%s': %w", filePath, ErrNotFound)
+}
+
+// statRoot describes the root directory of the commit at ref
+func (g *GiteaAdapter) statRoot(ctx context.Context, owner string, projectID uuid.UUID, ref string) (*FileNode, error) {
+	var commit gitea.Commit
+	err := g.doJSON(ctx, http.MethodGet,
+		fmt.Sprintf("/repos/%s/%s/git/commits/%s?stat=false&files=false&verification=false", owner, g.repoName(projectID), url.PathEscape(ref)),
+		nil, &commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat root at '%s': %w", ref, err)
+	}
+	if commit.RepoCommit == nil || commit.RepoCommit.Tree == nil {
+		return nil, fmt.Errorf("failed to stat root at '%s': commit has no tree", ref)
+	}
+	return &FileNode{Type: FileTypeDir, Mode: FileModeDir, SHA: commit.RepoCommit.Tree.SHA}, nil
+}
+
+// checkModified fails with ErrNotModified while path still has the SHA passed to WithIfNoneMatch
+func (g *GiteaAdapter) checkModified(ctx context.Context, o callOptions, projectID uuid.UUID, path string) error {
+	if o.ifNoneMatch == "" {
+		return nil
+	}
+	node, err := g.stat(ctx, o.owner, projectID, o.branch, path)
+	if err != nil {
+		return err
+	}
+	return o.notModified(path, node.SHA)
+}
+
+// GetFileAtRef retrieves a file as of ref, which may be a branch name, tag or commit SHA
+func (g *GiteaAdapter) GetFileAtRef(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) (*FileNode, error) {
+	return g.GetFile(ctx, projectID, path, append(slices.Clone(opts), WithBranch(ref))...)
+}
+
+// TreeHash returns the git tree SHA of directory path as of ref, "" for the root. An empty ref reads
+// the branch of WithBranch or the configured one, like GetFile. It changes exactly when something
+// under path does, so comparing it between reconciliation runs detects changes without listing files.
+func (g *GiteaAdapter) TreeHash(ctx context.Context, projectID uuid.UUID, ref, path string, opts ...Option) (string, error) {
+	g.logger.Info("TreeHash", "projectID", projectID, "ref", ref, "path", path)
+	o := g.callOptions(append(slices.Clone(opts), WithBranch(ref)))
+
+	if err := validatePath(path); err != nil {
+		return "", err
+	}
+
+	if path == "." {
+		path = ""
+	}
+	node, err := g.stat(ctx, o.owner, projectID, o.branch, path)
+	if err != nil {
+		return "", err
+	}
+	if node.Type != FileTypeDir {
+		return "", fmt.Errorf("failed to hash tree '%s': not a directory: %w", path, ErrNotFound)
+	}
+	return node.SHA, nil
 }
 
 // ListFiles retrieves files. If path is empty, lists root.
 // If path not set ("", "."), it recursively fetches all files and directories.
-func (g *GiteaAdapter) ListFiles(ctx context.Context, projectID uuid.UUID, path string) ([]FileNode, error) {
-	log.Printf("[Git Log] ListFiles projectID:%s, path:%s", projectID, path)
+func (g *GiteaAdapter) ListFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	g.logger.Info("ListFiles", "projectID", projectID, "path", path)
+	o := g.callOptions(opts)
+
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
 	isRecursive := false
 	switch path {
 	case ".", "":
 		path = "" // Empty string for root in Gitea API
 		isRecursive = true
 	}
+	if err := g.checkModified(ctx, o, projectID, path); err != nil {
+		return nil, err
+	}
+
+	var nodes []FileNode
+	var err error
+	if g.env.Cache == nil {
+		nodes, err = g.listContents(ctx, o.owner, projectID, o.branch, path, isRecursive)
+	} else {
+		nodes, err = g.cachedList(ctx, "list", o.owner, projectID, o.branch, path, func() ([]FileNode, error) {
+			return g.listContents(ctx, o.owner, projectID, o.branch, path, isRecursive)
+		})
+	}
+	return paginate(o, nodes), err
+}
 
-	entries, _, err := g.client.ListContents(g.env.Owner, projectID.String(), g.env.Branch, path)
+// listContents lists one directory, descending into subdirectories when isRecursive
+func (g *GiteaAdapter) listContents(ctx context.Context, owner string, projectID uuid.UUID, ref, path string, isRecursive bool) ([]FileNode, error) {
+	entries, resp, err := g.sdk(ctx).ListContents(owner, g.repoName(projectID), ref, path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list contents at path '%s': %w", path, err)
+		return nil, fmt.Errorf("failed to list contents at path '%s': %w", path, giteaError(resp, err))
 	}
 
 	var files []FileNode
 	for _, entry := range entries {
 		node := FileNode{
-			Name:     entry.Name,
-			Path:     entry.Path,
-			Target:   entry.Target,
-			SHA:      entry.SHA,
-			Size:     entry.Size,
+			Name:   entry.Name,
+			Path:   entry.Path,
+			Target: entry.Target,
+			SHA:    entry.SHA,
+			Size:   entry.Size,
 		}
 		switch entry.Type {
 		case "symlink":
 			node.Type = FileTypeSymlink
+		case "submodule":
+			node.Type = FileTypeSubmodule
+			if entry.SubmoduleGitURL != nil {
+				node.SubmoduleURL = *entry.SubmoduleGitURL
+			}
 		case "file":
 			node.Type = FileTypeFile
 		case "dir":
 			node.Type = FileTypeDir
 			// If recursive mode, fetch its contents
 			if isRecursive {
-				if node.Children, err = g.ListFiles(ctx, projectID, entry.Path); err != nil {
+				if node.Children, err = g.listContents(ctx, owner, projectID, ref, entry.Path, false); err != nil {
 					// Continue with other entries even if one directory fails
-					log.Printf("[Git Warning] Failed to list directory '%s': %v", entry.Path, err)
+					g.logger.Warn("Failed to list directory", "path", entry.Path, "err", err)
 				}
 			}
 		}
@@ -109,95 +475,658 @@ func (g *GiteaAdapter) ListFiles(ctx context.Context, projectID uuid.UUID, path
 	return files, nil
 }
 
+// ListFilesRecursive lists everything below path with a single git trees call,
+// populating Children for directories. An empty path lists the whole repository.
+func (g *GiteaAdapter) ListFilesRecursive(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	g.logger.Info("ListFilesRecursive", "projectID", projectID, "path", path)
+	o := g.callOptions(opts)
+
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
+	path = strings.Trim(path, "/")
+	if path == "." {
+		path = ""
+	}
+	if err := g.checkModified(ctx, o, projectID, path); err != nil {
+		return nil, err
+	}
+
+	var nodes []FileNode
+	var err error
+	if g.env.Cache == nil {
+		nodes, err = g.treeFiles(ctx, o.owner, projectID, o.branch, path)
+	} else {
+		nodes, err = g.cachedList(ctx, "tree", o.owner, projectID, o.branch, path, func() ([]FileNode, error) {
+			return g.treeFiles(ctx, o.owner, projectID, o.branch, path)
+		})
+	}
+	return paginate(o, nodes), err
+}
+
+// treeFiles builds the nested listing of everything below path from the recursive tree at ref
+func (g *GiteaAdapter) treeFiles(ctx context.Context, owner string, projectID uuid.UUID, ref, path string) ([]FileNode, error) {
+	entries, err := g.treeEntries(ctx, owner, projectID, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := ""
+	if path != "" {
+		prefix = path + "/"
+	}
+
+	found := path == ""
+	byParent := map[string][]FileNode{}
+	modules := g.submoduleURLs(ctx, owner, projectID, ref)
+	for _, entry := range entries {
+		if entry.Path == path && entry.Type == "tree" {
+			found = true
+		}
+		if !strings.HasPrefix(entry.Path, prefix) {
+			continue
+		}
+
+		node := gitEntryNode(entry, modules)
+		parent := ""
+		if i := strings.LastIndex(entry.Path, "/"); i >= 0 {
+			parent = entry.Path[:i]
+		}
+		byParent[parent] = append(byParent[parent], node)
+	}
+	if !found {
+		return nil, fmt.Errorf("failed to list contents at path '%s': %w", path, ErrNotFound)
+	}
+
+	return nestFileNodes(byParent, path), nil
+}
+
+// IterateFiles yields every entry below path, each directory before its contents, without
+// Children. The recursive tree is read a page at a time, so huge trees are never held in memory.
+func (g *GiteaAdapter) IterateFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) iter.Seq2[FileNode, error] {
+	return func(yield func(FileNode, error) bool) {
+		g.logger.Info("IterateFiles", "projectID", projectID, "path", path)
+		if err := validatePath(path); err != nil {
+			yield(FileNode{}, err)
+			return
+		}
+		o := g.callOptions(opts)
+		path = strings.Trim(path, "/")
+		if path == "." {
+			path = ""
+		}
+		prefix := ""
+		if path != "" {
+			prefix = path + "/"
+		}
+
+		found := path == ""
+		modules := g.submoduleURLs(ctx, o.owner, projectID, o.branch)
+		for page := 1; ; page++ {
+			tree, resp, err := g.sdk(ctx).GetTrees(o.owner, g.repoName(projectID), gitea.ListTreeOptions{
+				ListOptions: gitea.ListOptions{Page: page, PageSize: 1000},
+				Ref:         o.branch,
+				Recursive:   true,
+			})
+			if err != nil {
+				yield(FileNode{}, fmt.Errorf("failed to read tree at '%s': %w", o.branch, giteaError(resp, err)))
+				return
+			}
+			for _, entry := range tree.Entries {
+				if entry.Path == path && entry.Type == "tree" {
+					found = true
+				}
+				if !strings.HasPrefix(entry.Path, prefix) {
+					continue
+				}
+				if !yield(gitEntryNode(entry, modules), nil) {
+					return
+				}
+			}
+			if !tree.Truncated || len(tree.Entries) == 0 {
+				break
+			}
+		}
+		if !found {
+			yield(FileNode{}, fmt.Errorf("failed to list contents at path '%s': %w", path, ErrNotFound))
+		}
+	}
+}
+
+// gitEntryNode converts an entry of the git trees API; modules supplies submodule URLs
+func gitEntryNode(entry gitea.GitEntry, modules func() map[string]string) FileNode {
+	node := FileNode{
+		Name: entry.Path[strings.LastIndex(entry.Path, "/")+1:],
+		Path: entry.Path,
+		Mode: FileMode(entry.Mode),
+		SHA:  entry.SHA,
+		Size: entry.Size,
+	}
+	switch {
+	case entry.Type == "tree":
+		node.Type = FileTypeDir
+	case entry.Mode == "120000":
+		node.Type = FileTypeSymlink
+	case entry.Type == "commit":
+		node.Type = FileTypeSubmodule
+		node.SubmoduleURL = modules()[entry.Path]
+	default:
+		node.Type = FileTypeFile
+	}
+	return node
+}
+
+// submoduleURLs returns a function that reads the submodule URLs at ref on its first call,
+// so .gitmodules is only fetched when a listing contains a submodule
+func (g *GiteaAdapter) submoduleURLs(ctx context.Context, owner string, projectID uuid.UUID, ref string) func() map[string]string {
+	return sync.OnceValue(func() map[string]string {
+		raw, resp, err := g.sdk(ctx).GetFile(owner, g.repoName(projectID), ref, gitmodulesPath)
+		if err != nil {
+			g.logger.Warn("Failed to read submodule URLs", "projectID", projectID, "err", giteaError(resp, err))
+			return nil
+		}
+		urls, err := parseGitmodules(raw)
+		if err != nil {
+			g.logger.Warn("Failed to read submodule URLs", "projectID", projectID, "err", err)
+		}
+		return urls
+	})
+}
+
 // CommitFile creates or updates a file
-func (g *GiteaAdapter) CommitFile(ctx context.Context, projectID uuid.UUID, path, content, message string) error {
-	log.Printf("[Git Log] CommitFile projectID:%s, path:%s, message:%s", projectID, path, message)
+func (g *GiteaAdapter) CommitFile(ctx context.Context, projectID uuid.UUID, path, content, message string, opts ...Option) error {
+	g.logger.Info("CommitFile", "projectID", projectID, "path", path, "message", message)
+	o := g.callOptions(opts)
 
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	normalized, err := g.env.Normalization.apply(path, []byte(content))
+	if err != nil {
+		return err
+	}
+	content = string(normalized)
+
+	if err := g.env.Limits.checkFile(path, int64(len(content))); err != nil {
+		return err
+	}
+	if err := g.checkRepoSize(ctx, o, projectID, int64(len(content))); err != nil {
+		return err
+	}
 	b64Content := base64.StdEncoding.EncodeToString([]byte(content))
 
 	// Check if file exists to decide between Create or Update
-	if existing, _, err := g.client.GetContents(g.env.Owner, projectID.String(), g.env.Branch, path); err == nil {
+	existing, err := g.stat(ctx, o.owner, projectID, o.branch, path)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to check existing file: %w", err)
+	}
+	current := ""
+	if existing != nil {
+		current = existing.SHA
+	}
+	if err := checkExpectedSHA(path, o.expectedSHA, current); err != nil {
+		return err
+	}
+	if g.signer != nil {
+		return g.pushChanges(ctx, projectID, o, message, changesEdit([]FileChange{{Path: path, Content: content}}))
+	}
+	author, err := g.author(ctx, o)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
 		// File exists -> Update
-		_, _, err = g.client.UpdateFile(g.env.Owner, projectID.String(), path, gitea.UpdateFileOptions{
+		file, resp, err := g.sdk(ctx).UpdateFile(o.owner, g.repoName(projectID), path, gitea.UpdateFileOptions{
 			FileOptions: gitea.FileOptions{
-				Message:    message,
-				BranchName: g.env.Branch,
-				Author:     *g.identity,
+				Message:    o.message(message),
+				BranchName: o.branch,
+				Author:     author,
 				Committer:  *g.identity,
 			},
 			Content: b64Content,
 			SHA:     existing.SHA,
 		})
-		return err
+		if err != nil {
+			return giteaError(resp, err)
+		}
+		g.committed(o, file)
+		return nil
 	}
 
 	// File does not exist -> Create
-	_, _, err := g.client.CreateFile(g.env.Owner, projectID.String(), path, gitea.CreateFileOptions{
+	file, resp, err := g.sdk(ctx).CreateFile(o.owner, g.repoName(projectID), path, gitea.CreateFileOptions{
 		FileOptions: gitea.FileOptions{
-			Message:    message,
-			BranchName: g.env.Branch,
-			Author:     *g.identity,
+			Message:    o.message(message),
+			BranchName: o.branch,
+			Author:     author,
 			Committer:  *g.identity,
 		},
 		Content: b64Content,
 	})
-	return err
+	if err != nil {
+		return giteaError(resp, err)
+	}
+	g.committed(o, file)
+	return nil
+}
+
+// CommitFileBytes creates or updates a file with binary-safe content
+func (g *GiteaAdapter) CommitFileBytes(ctx context.Context, projectID uuid.UUID, path string, content []byte, message string, opts ...Option) error {
+	return g.WriteFile(ctx, projectID, path, bytes.NewReader(content), message, opts...)
+}
+
+// CommitFiles applies all changes in a single commit using Gitea's multi-file contents API.
+// The contents API cannot set file modes, so changes with a Mode are pushed over git instead.
+func (g *GiteaAdapter) CommitFiles(ctx context.Context, projectID uuid.UUID, files []FileChange, message string, opts ...Option) error {
+	g.logger.Info("CommitFiles", "projectID", projectID, "files", len(files), "message", message)
+	o := g.callOptions(opts)
+
+	if err := validateChanges(files); err != nil {
+		return err
+	}
+	files, err := g.env.Normalization.applyChanges(files)
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	for _, f := range files {
+		if err := g.env.Limits.checkFile(f.Path, int64(len(f.data()))); err != nil {
+			return err
+		}
+		size += int64(len(f.data()))
+	}
+	if err := g.checkRepoSize(ctx, o, projectID, size); err != nil {
+		return err
+	}
+
+	if g.signer != nil || slices.ContainsFunc(files, func(f FileChange) bool { return f.Mode != "" }) {
+		if err := g.pushChanges(ctx, projectID, o, message, changesEdit(files)); err != nil {
+			return fmt.Errorf("failed to commit %d files: %w", len(files), err)
+		}
+		return nil
+	}
+
+	// Only walk the tree when some change needs its operation or SHA resolved
+	var existing map[string]string
+	for _, f := range files {
+		if f.Operation == "" || (f.Operation != FileOperationCreate && f.SHA == "") {
+			var err error
+			if existing, err = g.treeIndex(ctx, o.owner, projectID, o.branch); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	author, err := g.author(ctx, o)
+	if err != nil {
+		return err
+	}
+	ops := make([]changeFileOperation, 0, len(files))
+	for _, f := range files {
+		src := sourcePath(f)
+		sha, exists := existing[src]
+		if existing == nil {
+			exists = f.Operation != FileOperationCreate
+		}
+		operation, err := resolveOperation(f, exists)
+		if err != nil {
+			return err
+		}
+
+		op := changeFileOperation{
+			Operation: string(operation),
+			Path:      f.Path,
+			FromPath:  f.FromPath,
+			SHA:       f.SHA,
+		}
+		if op.SHA == "" && operation != FileOperationCreate {
+			op.SHA = sha
+		}
+		if operation != FileOperationDelete {
+			op.Content = base64.StdEncoding.EncodeToString(f.data())
+		}
+		ops = append(ops, op)
+	}
+
+	opt := changeFilesOptions{
+		FileOptions: gitea.FileOptions{
+			Message:    o.message(message),
+			BranchName: o.branch,
+			Author:     author,
+			Committer:  *g.identity,
+		},
+		Files: ops,
+	}
+	path := fmt.Sprintf("/repos/%s/%s/contents", o.owner, g.repoName(projectID))
+	var out gitea.FileResponse
+	if err := g.doJSON(ctx, http.MethodPost, path, opt, &out); err != nil {
+		return fmt.Errorf("failed to commit %d files: %w", len(files), err)
+	}
+	g.committed(o, &out)
+	return nil
+}
+
+// NewChangeSet starts a change set applied as one commit by CommitFiles
+func (g *GiteaAdapter) NewChangeSet(projectID uuid.UUID) *ChangeSet {
+	return newChangeSet(g, projectID)
+}
+
+// PlanChanges reports what CommitFiles would do with changes, without committing
+func (g *GiteaAdapter) PlanChanges(ctx context.Context, projectID uuid.UUID, changes []FileChange, opts ...Option) (*Plan, error) {
+	return planChanges(ctx, g, g.logger, projectID, changes, opts)
 }
 
 // DeleteFile implementation (Basic)
-func (g *GiteaAdapter) DeleteFile(ctx context.Context, projectID uuid.UUID, path, message string) error {
-	log.Printf("[Git Log] DeleteFile projectID:%s, path:%s, message:%s", projectID, path, message)
+func (g *GiteaAdapter) DeleteFile(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	g.logger.Info("DeleteFile", "projectID", projectID, "path", path, "message", message)
+	o := g.callOptions(opts)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
 
 	// Gitea requires the SHA of the file to delete it
-	existing, _, err := g.client.GetContents(g.env.Owner, projectID.String(), g.env.Branch, path)
+	existing, err := g.stat(ctx, o.owner, projectID, o.branch, path)
 	if err != nil {
 		return fmt.Errorf("file not found for deletion: %w", err)
 	}
+	if g.signer != nil {
+		return g.pushChanges(ctx, projectID, o, message, changesEdit([]FileChange{{Operation: FileOperationDelete, Path: path}}))
+	}
+	author, err := g.author(ctx, o)
+	if err != nil {
+		return err
+	}
 
-	_, err = g.client.DeleteFile(g.env.Owner, projectID.String(), path, gitea.DeleteFileOptions{
+	// The SDK drops the response, which names the commit
+	var out gitea.FileResponse
+	err = g.doJSON(ctx, http.MethodDelete, fmt.Sprintf("/repos/%s/%s/contents/%s", o.owner, g.repoName(projectID), escapePath(path)), gitea.DeleteFileOptions{
 		FileOptions: gitea.FileOptions{
-			Message:    message,
-			BranchName: g.env.Branch,
+			Message:    o.message(message),
+			BranchName: o.branch,
+			Author:     author,
+			Committer:  *g.identity,
 		},
 		SHA: existing.SHA,
-	})
-	return err
+	}, &out)
+	if err != nil {
+		return err
+	}
+	g.committed(o, &out)
+	return nil
 }
 
-// CreateRepository creates a new private repository and returns its full name (owner/name)
-func (g *GiteaAdapter) CreateRepository(ctx context.Context, projectID uuid.UUID) (string, error) {
-	log.Printf("[Git Log] Creating repository: %s", projectID)
+// DeletePath removes a file or a whole directory tree in a single commit
+func (g *GiteaAdapter) DeletePath(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	return deletePath(ctx, g, g.logger, projectID, path, message, opts)
+}
+
+// SearchFiles returns every line of a text file on the branch that contains query
+func (g *GiteaAdapter) SearchFiles(ctx context.Context, projectID uuid.UUID, query string, opts ...Option) ([]SearchMatch, error) {
+	return searchFiles(ctx, g, g.logger, projectID, query, opts)
+}
+
+// MoveFile renames a file in a single commit
+func (g *GiteaAdapter) MoveFile(ctx context.Context, projectID uuid.UUID, oldPath, newPath, message string, opts ...Option) error {
+	if err := validatePaths(oldPath, newPath); err != nil {
+		return err
+	}
+	return moveFile(ctx, g, g.logger, projectID, oldPath, newPath, message, opts)
+}
+
+// CopyFile copies a file from one project repository into another
+func (g *GiteaAdapter) CopyFile(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, srcPath, dstPath, message string) error {
+	return copyFiles(ctx, g, g.logger, srcProjectID, dstProjectID, map[string]string{srcPath: dstPath}, message)
+}
+
+// CopyFiles copies files (source path -> destination path) between project repositories in a single commit
+func (g *GiteaAdapter) CopyFiles(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, paths map[string]string, message string) error {
+	return copyFiles(ctx, g, g.logger, srcProjectID, dstProjectID, paths, message)
+}
+
+// ImportArchive unpacks a tar or tar.gz stream and commits its files in a single commit
+func (g *GiteaAdapter) ImportArchive(ctx context.Context, projectID uuid.UUID, r io.Reader, message string, opts ...Option) error {
+	return importArchive(ctx, g, g.logger, projectID, r, message, opts)
+}
+
+// CreateRepository creates a new repository and returns its full name (owner/name).
+// With WithIdempotent, an existing repository is returned instead of failing; with WithOwner
+// it is created in that organization, see EnsureOrganization. WithRepositoryOptions overrides
+// the description, default branch, topics, visibility and auto-init of the configuration.
+func (g *GiteaAdapter) CreateRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) (string, error) {
+	g.logger.Info("CreateRepository", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	if o.idempotent {
+		if repo, resp, err := g.sdk(ctx).GetRepo(o.owner, g.repoName(projectID)); err == nil {
+			g.logger.Info("Repository already exists", "projectID", projectID)
+			return repo.FullName, nil
+		} else if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return "", fmt.Errorf("failed to check gitea repository: %w", giteaError(resp, err))
+		}
+	}
+
+	// Render the presets first so an unknown one fails before anything is created
+	var presetFiles []FileChange
+	if len(o.repo.Presets) > 0 {
+		files, err := PresetFiles(o.repo.Presets...)
+		if err != nil {
+			return "", err
+		}
+		for _, f := range files {
+			presetFiles = append(presetFiles, FileChange{Path: f.Path, Content: *f.Content})
+		}
+	}
+	description := cmp.Or(o.repo.Description, "Managed by GitAPI")
+	var readme string
+	if o.repo.Readme != "" {
+		var err error
+		readme, err = renderReadme(o.repo.Readme, ReadmeData{
+			ProjectID:   projectID,
+			Name:        g.repoName(projectID),
+			Owner:       o.owner,
+			Description: description,
+			License:     o.repo.License,
+			Metadata:    o.repo.Metadata,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
 
 	opt := gitea.CreateRepoOption{
-		Name:          projectID.String(),
-		Description:   "Managed by GitAPI",
-		Private:       g.env.CreateRepoPrivate,
-		AutoInit:      g.env.CreateRepoInit, // Initializes with a default branch so it's immediately usable
-		DefaultBranch: g.env.Branch,
+		Name:        g.repoName(projectID),
+		Description: description,
+		Private:     boolOr(o.repo.Private, g.env.CreateRepoPrivate),
+		// Initializes with a default branch so it's immediately usable; Gitea only applies a license on init
+		AutoInit:      boolOr(o.repo.AutoInit, g.env.CreateRepoInit) || o.repo.License != "",
+		DefaultBranch: cmp.Or(o.repo.DefaultBranch, g.env.Branch),
+		License:       o.repo.License,
 	}
 
-	repo, _, err := g.client.CreateRepo(opt)
+	// The configured owner is the token's own account; overrides are organizations
+	create := g.sdk(ctx).CreateRepo
+	if o.owner != g.env.Owner {
+		create = func(opt gitea.CreateRepoOption) (*gitea.Repository, *gitea.Response, error) {
+			return g.sdk(ctx).CreateOrgRepo(o.owner, opt)
+		}
+	}
+	repo, resp, err := create(opt)
 	if err != nil {
-		return "", fmt.Errorf("failed to create gitea repository: %w", err)
+		// Lost a race with a concurrent create
+		if o.idempotent && resp != nil && resp.StatusCode == http.StatusConflict {
+			return o.owner + "/" + g.repoName(projectID), nil
+		}
+		return "", fmt.Errorf("failed to create gitea repository: %w", giteaError(resp, err))
 	}
 
+	if len(o.repo.Topics) > 0 {
+		if resp, err := g.sdk(ctx).SetRepoTopics(repo.Owner.UserName, repo.Name, o.repo.Topics); err != nil {
+			return "", fmt.Errorf("failed to set repository topics: %w", giteaError(resp, err))
+		}
+	}
+	if len(presetFiles) > 0 {
+		if err := g.CommitFiles(ctx, projectID, presetFiles, presetMessage, WithOwner(o.owner), WithBranch(opt.DefaultBranch)); err != nil {
+			return "", fmt.Errorf("failed to add preset files: %w", err)
+		}
+	}
+	if readme != "" {
+		files := []FileChange{{Path: "README.md", Content: readme}}
+		if err := g.CommitFiles(ctx, projectID, files, readmeMessage, WithOwner(o.owner), WithBranch(opt.DefaultBranch)); err != nil {
+			return "", fmt.Errorf("failed to add README: %w", err)
+		}
+	}
 	return repo.FullName, nil
 }
 
-// ScaffoldProjectFiles creates or updates multiple files
-func (g *GiteaAdapter) ScaffoldProjectFiles(ctx context.Context, projectID uuid.UUID, files []FileNode) error {
-	log.Printf("[Git] Starting Serial Scaffold for %s (%d files)", projectID, len(files))
+// boolOr returns *b, or fallback when b is nil
+func boolOr(b *bool, fallback bool) bool {
+	if b == nil {
+		return fallback
+	}
+	return *b
+}
+
+// RepositoryExists reports whether the project repository exists
+func (g *GiteaAdapter) RepositoryExists(ctx context.Context, projectID uuid.UUID, opts ...Option) (bool, error) {
+	o := g.callOptions(opts)
+
+	_, resp, err := g.sdk(ctx).GetRepo(o.owner, g.repoName(projectID))
+	if err == nil {
+		return true, nil
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check gitea repository: %w", giteaError(resp, err))
+}
+
+// ScaffoldProjectFiles creates or updates multiple files, reporting the outcome per path
+func (g *GiteaAdapter) ScaffoldProjectFiles(ctx context.Context, projectID uuid.UUID, files []FileNode) (*ScaffoldResult, error) {
+	return g.ScaffoldProjectFilesWithOptions(ctx, projectID, files, ScaffoldOptions{})
+}
 
-	for i, file := range files {
-		log.Printf("[%d/%d] Committing %s...", i+1, len(files), file.Path)
-		msg := fmt.Sprintf("Scaffold path: %s", file.Path)
-		err := g.CommitFile(ctx, projectID, file.Path, *file.Content, msg)
+// ScaffoldProjectFilesWithOptions creates or updates multiple files using a bounded worker pool
+func (g *GiteaAdapter) ScaffoldProjectFilesWithOptions(ctx context.Context, projectID uuid.UUID, files []FileNode, opts ScaffoldOptions) (*ScaffoldResult, error) {
+	if err := g.env.Limits.checkScaffold(len(files)); err != nil {
+		return nil, err
+	}
+	return scaffold(ctx, g, g.logger, projectID, files, opts, g.env.Normalization)
+}
+
+// ScaffoldFromTemplates renders fsys with data (see renderTemplates) and scaffolds the result
+func (g *GiteaAdapter) ScaffoldFromTemplates(ctx context.Context, projectID uuid.UUID, fsys fs.FS, data any) (*ScaffoldResult, error) {
+	g.logger.Info("ScaffoldFromTemplates", "projectID", projectID)
+
+	files, err := renderTemplates(fsys, data)
+	if err != nil {
+		return nil, err
+	}
+	return g.ScaffoldProjectFiles(ctx, projectID, files)
+}
+
+// ScaffoldFromFS commits every file below root in fsys, e.g. an embed.FS or os.DirFS,
+// at the same path relative to root
+func (g *GiteaAdapter) ScaffoldFromFS(ctx context.Context, projectID uuid.UUID, fsys fs.FS, root string) (*ScaffoldResult, error) {
+	g.logger.Info("ScaffoldFromFS", "projectID", projectID, "root", root)
+
+	files, err := subFiles(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	return g.ScaffoldProjectFiles(ctx, projectID, files)
+}
+
+// MergeScaffold upgrades files scaffolded from baseFiles to newFiles with a three-way merge per
+// file, so edits made in the repository survive. Conflicts are resolved according to strategy
+// and listed in the result; the merge is committed at once.
+func (g *GiteaAdapter) MergeScaffold(ctx context.Context, projectID uuid.UUID, baseFiles, newFiles []FileNode, strategy ScaffoldMergeStrategy) (*ScaffoldMergeResult, error) {
+	return mergeScaffold(ctx, g, g.logger, projectID, baseFiles, newFiles, strategy)
+}
+
+// callOptions resolves opts against the configured branch and owner
+func (g *GiteaAdapter) callOptions(opts []Option) callOptions {
+	o := newCallOptions(g.env.Branch, opts)
+	if o.owner == "" {
+		o.owner = g.env.Owner
+	}
+	o.format = g.env.CommitFormat
+	return o
+}
+
+// committed reports the commit a contents API response describes to the withCommitHook hook
+func (g *GiteaAdapter) committed(o callOptions, file *gitea.FileResponse) {
+	if file != nil && file.Commit != nil {
+		o.committed(file.Commit.SHA)
+	}
+}
+
+// author resolves who a commit is attributed to: the WithAuthor identity, else the ContextWithSudo
+// user, else the adapter identity
+func (g *GiteaAdapter) author(ctx context.Context, o callOptions) (gitea.Identity, error) {
+	if o.authorName != "" {
+		return gitea.Identity{Name: o.authorName, Email: o.authorEmail}, nil
+	}
+	sudo := sudoUser(ctx)
+	if sudo == "" {
+		return *g.identity, nil
+	}
+	user, resp, err := g.sdk(ctx).GetUserInfo(sudo)
+	if err != nil {
+		return gitea.Identity{}, fmt.Errorf("failed to get user '%s': %w", sudo, giteaError(resp, err))
+	}
+	return gitea.Identity{Name: cmp.Or(user.FullName, user.UserName), Email: user.Email}, nil
+}
+
+// treeIndex maps every blob path under ref to its SHA using the recursive git trees API
+func (g *GiteaAdapter) treeIndex(ctx context.Context, owner string, projectID uuid.UUID, ref string) (map[string]string, error) {
+	entries, err := g.treeEntries(ctx, owner, projectID, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]string{}
+	for _, entry := range entries {
+		if entry.Type == "blob" {
+			index[entry.Path] = entry.SHA
+		}
+	}
+	return index, nil
+}
+
+// treeEntries returns every entry under ref using the recursive git trees API, following pagination
+func (g *GiteaAdapter) treeEntries(ctx context.Context, owner string, projectID uuid.UUID, ref string) ([]gitea.GitEntry, error) {
+	var entries []gitea.GitEntry
+	for page := 1; ; page++ {
+		tree, resp, err := g.sdk(ctx).GetTrees(owner, g.repoName(projectID), gitea.ListTreeOptions{
+			ListOptions: gitea.ListOptions{Page: page, PageSize: 1000},
+			Ref:         ref,
+			Recursive:   true,
+		})
 		if err != nil {
-			log.Printf("[Git Err] Scaffold project: %s path:%s err: %s",
-				projectID, file.Path, err.Error())
+			return nil, fmt.Errorf("failed to read tree at '%s': %w", ref, giteaError(resp, err))
+		}
+		entries = append(entries, tree.Entries...)
+		if !tree.Truncated || len(tree.Entries) == 0 {
+			return entries, nil
 		}
 	}
+}
 
-	log.Printf("[Git] Scaffold completed successfully for %s", projectID)
-	return nil
+// nestFileNodes arranges flat entries into a tree and returns the direct children of dir
+func nestFileNodes(byParent map[string][]FileNode, dir string) []FileNode {
+	nodes := byParent[dir]
+	for i := range nodes {
+		if nodes[i].Type == FileTypeDir {
+			nodes[i].Children = nestFileNodes(byParent, nodes[i].Path)
+		}
+	}
+	return nodes
 }
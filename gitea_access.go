@@ -0,0 +1,58 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// AddCollaborator grants a user permission on the repository, updating it when the user
+// is already a collaborator
+func (g *GiteaAdapter) AddCollaborator(ctx context.Context, projectID uuid.UUID, username string, permission Permission, opts ...Option) error {
+	g.logger.Info("AddCollaborator", "projectID", projectID, "username", username, "permission", permission)
+	o := g.callOptions(opts)
+
+	mode := gitea.AccessMode(permission)
+	if resp, err := g.sdk(ctx).AddCollaborator(o.owner, g.repoName(projectID), username, gitea.AddCollaboratorOption{
+		Permission: &mode,
+	}); err != nil {
+		return fmt.Errorf("failed to add collaborator '%s': %w", username, giteaError(resp, err))
+	}
+	return nil
+}
+
+// RemoveCollaborator revokes a user's direct access to the repository
+func (g *GiteaAdapter) RemoveCollaborator(ctx context.Context, projectID uuid.UUID, username string, opts ...Option) error {
+	g.logger.Info("RemoveCollaborator", "projectID", projectID, "username", username)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).DeleteCollaborator(o.owner, g.repoName(projectID), username); err != nil {
+		return fmt.Errorf("failed to remove collaborator '%s': %w", username, giteaError(resp, err))
+	}
+	return nil
+}
+
+// AddTeam gives an organization team access to the repository with the team's own permission.
+// Only repositories owned by an organization can have teams.
+func (g *GiteaAdapter) AddTeam(ctx context.Context, projectID uuid.UUID, team string, opts ...Option) error {
+	g.logger.Info("AddTeam", "projectID", projectID, "team", team)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).AddRepoTeam(o.owner, g.repoName(projectID), team); err != nil {
+		return fmt.Errorf("failed to add team '%s': %w", team, giteaError(resp, err))
+	}
+	return nil
+}
+
+// RemoveTeam revokes an organization team's access to the repository
+func (g *GiteaAdapter) RemoveTeam(ctx context.Context, projectID uuid.UUID, team string, opts ...Option) error {
+	g.logger.Info("RemoveTeam", "projectID", projectID, "team", team)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).RemoveRepoTeam(o.owner, g.repoName(projectID), team); err != nil {
+		return fmt.Errorf("failed to remove team '%s': %w", team, giteaError(resp, err))
+	}
+	return nil
+}
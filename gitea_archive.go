@@ -0,0 +1,41 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// DownloadArchive streams the repository at ref (branch, tag or SHA) into w as a single archive.
+// Files are placed under a top-level directory named after the repository.
+func (g *GiteaAdapter) DownloadArchive(ctx context.Context, projectID uuid.UUID, ref string, format ArchiveFormat, w io.Writer, opts ...Option) error {
+	if ref == "" {
+		ref = g.env.Branch
+	}
+	g.logger.Info("DownloadArchive", "projectID", projectID, "ref", ref, "format", format)
+	o := g.callOptions(opts)
+
+	var ext gitea.ArchiveType
+	switch format {
+	case ArchiveTarGz:
+		ext = gitea.TarGZArchive
+	case ArchiveZip:
+		ext = gitea.ZipArchive
+	default:
+		return fmt.Errorf("unsupported archive format '%s'", format)
+	}
+
+	body, resp, err := g.sdk(ctx).GetArchiveReader(o.owner, g.repoName(projectID), ref, ext)
+	if err != nil {
+		return fmt.Errorf("failed to download archive at '%s': %w", ref, giteaError(resp, err))
+	}
+	defer body.Close()
+
+	if _, err := io.Copy(w, body); err != nil {
+		return fmt.Errorf("failed to download archive at '%s': %w", ref, err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// CreateBranch creates a branch from another branch. An empty from uses the configured branch.
+func (g *GiteaAdapter) CreateBranch(ctx context.Context, projectID uuid.UUID, name, from string, opts ...Option) (*Branch, error) {
+	o := g.callOptions(opts)
+	if from == "" {
+		from = o.branch
+	}
+	g.logger.Info("CreateBranch", "projectID", projectID, "branch", name, "from", from)
+
+	branch, resp, err := g.sdk(ctx).CreateBranch(o.owner, g.repoName(projectID), gitea.CreateBranchOption{
+		BranchName:    name,
+		OldBranchName: from,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create branch '%s': %w", name, giteaError(resp, err))
+	}
+	return toBranch(branch), nil
+}
+
+// DeleteBranch removes a branch
+func (g *GiteaAdapter) DeleteBranch(ctx context.Context, projectID uuid.UUID, name string, opts ...Option) error {
+	g.logger.Info("DeleteBranch", "projectID", projectID, "branch", name)
+	o := g.callOptions(opts)
+
+	deleted, resp, err := g.sdk(ctx).DeleteRepoBranch(o.owner, g.repoName(projectID), name)
+	if err != nil {
+		return fmt.Errorf("failed to delete branch '%s': %w", name, giteaError(resp, err))
+	}
+	if !deleted {
+		return fmt.Errorf("failed to delete branch '%s'", name)
+	}
+	return nil
+}
+
+// GetBranch retrieves a single branch
+func (g *GiteaAdapter) GetBranch(ctx context.Context, projectID uuid.UUID, name string, opts ...Option) (*Branch, error) {
+	g.logger.Info("GetBranch", "projectID", projectID, "branch", name)
+	o := g.callOptions(opts)
+
+	branch, resp, err := g.sdk(ctx).GetRepoBranch(o.owner, g.repoName(projectID), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch '%s': %w", name, giteaError(resp, err))
+	}
+	return toBranch(branch), nil
+}
+
+// ListBranches retrieves all branches, following pagination
+// unless WithPage selects a single page
+func (g *GiteaAdapter) ListBranches(ctx context.Context, projectID uuid.UUID, opts ...Option) ([]Branch, error) {
+	g.logger.Info("ListBranches", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	var branches []Branch
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListRepoBranches(o.owner, g.repoName(projectID), gitea.ListRepoBranchesOptions{
+			ListOptions: page,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to list branches: %w", giteaError(resp, err))
+		}
+		for _, b := range batch {
+			branches = append(branches, *toBranch(b))
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+// SetDefaultBranch makes branch the default of the project repository, e.g. to migrate from master
+// to main after creating main with CreateBranch. Adapter calls keep using the configured branch.
+func (g *GiteaAdapter) SetDefaultBranch(ctx context.Context, projectID uuid.UUID, branch string, opts ...Option) error {
+	g.logger.Info("SetDefaultBranch", "projectID", projectID, "branch", branch)
+	o := g.callOptions(opts)
+
+	repo, resp, err := g.sdk(ctx).EditRepo(o.owner, g.repoName(projectID), gitea.EditRepoOption{
+		DefaultBranch: &branch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set default branch: %w", giteaError(resp, err))
+	}
+	// Gitea ignores branches that do not exist instead of rejecting them
+	if repo.DefaultBranch != branch {
+		return fmt.Errorf("failed to set default branch '%s': %w", branch, ErrNotFound)
+	}
+	return nil
+}
+
+// Housekeep deletes the stale and merged branches policy selects and, with policy.GC, triggers
+// garbage collection. Branches are merged when the repository's default branch contains them.
+// Gitea has no per-repository gc endpoint, so GC runs over the whole instance.
+func (g *GiteaAdapter) Housekeep(ctx context.Context, projectID uuid.UUID, policy HousekeepingPolicy, opts ...Option) (*HousekeepingReport, error) {
+	g.logger.Info("Housekeep", "projectID", projectID, "gc", policy.GC, "staleAfter", policy.StaleAfter, "deleteMerged", policy.DeleteMerged)
+	o := g.callOptions(opts)
+
+	repo, resp, err := g.sdk(ctx).GetRepo(o.owner, g.repoName(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", giteaError(resp, err))
+	}
+	defaultBranch := cmp.Or(repo.DefaultBranch, g.env.Branch)
+
+	branches, err := g.ListBranches(ctx, projectID, WithOwner(o.owner))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &HousekeepingReport{DeletedBranches: []string{}}
+	now := time.Now()
+	for _, branch := range branches {
+		if !policy.expendable(branch, defaultBranch) {
+			continue
+		}
+		remove := policy.stale(branch, now)
+		if !remove && policy.DeleteMerged {
+			compare, resp, err := g.sdk(ctx).CompareCommits(o.owner, g.repoName(projectID), defaultBranch, branch.Name)
+			if err != nil {
+				return report, fmt.Errorf("failed to compare branch '%s': %w", branch.Name, giteaError(resp, err))
+			}
+			remove = compare.TotalCommits == 0
+		}
+		if !remove {
+			continue
+		}
+		if !policy.DryRun {
+			if err := g.DeleteBranch(ctx, projectID, branch.Name, WithOwner(o.owner)); err != nil {
+				return report, err
+			}
+		}
+		report.DeletedBranches = append(report.DeletedBranches, branch.Name)
+	}
+
+	if policy.GC && !policy.DryRun {
+		if resp, err := g.sdk(ctx).RunCronTasks(gcCronTask); err != nil {
+			return report, fmt.Errorf("failed to run garbage collection: %w", giteaError(resp, err))
+		}
+		report.GarbageCollected = true
+	}
+	return report, nil
+}
+
+func toBranch(b *gitea.Branch) *Branch {
+	branch := &Branch{
+		Name:      b.Name,
+		Protected: b.Protected,
+	}
+	if b.Commit != nil {
+		branch.CommitSHA = b.Commit.ID
+		branch.UpdatedAt = b.Commit.Timestamp
+	}
+	return branch
+}
+
+// ProtectBranch applies rules to branch, replacing any existing protection. Force pushes
+// and deletion are always blocked on a protected branch.
+func (g *GiteaAdapter) ProtectBranch(ctx context.Context, projectID uuid.UUID, branch string, rules BranchProtection, opts ...Option) error {
+	g.logger.Info("ProtectBranch", "projectID", projectID, "branch", branch,
+		"approvals", rules.RequiredApprovals, "statusChecks", rules.StatusChecks)
+	o := g.callOptions(opts)
+
+	client := g.sdk(ctx)
+	_, resp, err := client.GetBranchProtection(o.owner, g.repoName(projectID), branch)
+	if err != nil {
+		if err = giteaError(resp, err); !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to get protection of branch '%s': %w", branch, err)
+		}
+
+		_, resp, err = client.CreateBranchProtection(o.owner, g.repoName(projectID), gitea.CreateBranchProtectionOption{
+			RuleName:               branch,
+			EnablePush:             true,
+			EnablePushWhitelist:    len(rules.PushUsers) > 0,
+			PushWhitelistUsernames: rules.PushUsers,
+			EnableStatusCheck:      len(rules.StatusChecks) > 0,
+			StatusCheckContexts:    rules.StatusChecks,
+			RequiredApprovals:      rules.RequiredApprovals,
+			BlockOnRejectedReviews: rules.BlockOnRejectedReviews,
+			BlockOnOutdatedBranch:  rules.BlockOnOutdatedBranch,
+			DismissStaleApprovals:  rules.DismissStaleApprovals,
+			RequireSignedCommits:   rules.RequireSignedCommits,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to protect branch '%s': %w", branch, giteaError(resp, err))
+		}
+		return nil
+	}
+
+	_, resp, err = client.EditBranchProtection(o.owner, g.repoName(projectID), branch, gitea.EditBranchProtectionOption{
+		EnablePush:             gitea.OptionalBool(true),
+		EnablePushWhitelist:    gitea.OptionalBool(len(rules.PushUsers) > 0),
+		PushWhitelistUsernames: rules.PushUsers,
+		EnableStatusCheck:      gitea.OptionalBool(len(rules.StatusChecks) > 0),
+		StatusCheckContexts:    rules.StatusChecks,
+		RequiredApprovals:      gitea.OptionalInt64(rules.RequiredApprovals),
+		BlockOnRejectedReviews: gitea.OptionalBool(rules.BlockOnRejectedReviews),
+		BlockOnOutdatedBranch:  gitea.OptionalBool(rules.BlockOnOutdatedBranch),
+		DismissStaleApprovals:  gitea.OptionalBool(rules.DismissStaleApprovals),
+		RequireSignedCommits:   gitea.OptionalBool(rules.RequireSignedCommits),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update protection of branch '%s': %w", branch, giteaError(resp, err))
+	}
+	return nil
+}
+
+// UnprotectBranch removes the protection rule of branch
+func (g *GiteaAdapter) UnprotectBranch(ctx context.Context, projectID uuid.UUID, branch string, opts ...Option) error {
+	g.logger.Info("UnprotectBranch", "projectID", projectID, "branch", branch)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).DeleteBranchProtection(o.owner, g.repoName(projectID), branch); err != nil {
+		return fmt.Errorf("failed to unprotect branch '%s': %w", branch, giteaError(resp, err))
+	}
+	return nil
+}
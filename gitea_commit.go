@@ -0,0 +1,265 @@
+package git
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// maxBlameCommits bounds the history BlameFile replays; older lines are attributed to the oldest commit replayed
+const maxBlameCommits = 200
+
+// ListCommits returns one page of history, newest first. A non-empty path limits
+// the history to commits touching that file or directory.
+func (g *GiteaAdapter) ListCommits(ctx context.Context, projectID uuid.UUID, path string, opts CommitListOptions, options ...Option) ([]Commit, error) {
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+	if opts.Ref == "" {
+		opts.Ref = g.env.Branch
+	}
+	g.logger.Info("ListCommits", "projectID", projectID, "path", path, "ref", opts.Ref, "page", opts.Page)
+	o := g.callOptions(options)
+
+	commits, resp, err := g.sdk(ctx).ListRepoCommits(o.owner, g.repoName(projectID), gitea.ListCommitOptions{
+		ListOptions:  gitea.ListOptions{Page: max(opts.Page, 1), PageSize: opts.Limit},
+		SHA:          opts.Ref,
+		Path:         path,
+		Verification: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", giteaError(resp, err))
+	}
+
+	out := make([]Commit, 0, len(commits))
+	for _, c := range commits {
+		out = append(out, toCommit(c))
+	}
+	return out, nil
+}
+
+// GetDiff returns the per-file changes needed to turn base into head (branches, tags or SHAs)
+func (g *GiteaAdapter) GetDiff(ctx context.Context, projectID uuid.UUID, base, head string, opts ...Option) (*Diff, error) {
+	g.logger.Info("GetDiff", "projectID", projectID, "base", base, "head", head)
+	o := g.callOptions(opts)
+
+	baseIndex, err := g.treeIndex(ctx, o.owner, projectID, base)
+	if err != nil {
+		return nil, err
+	}
+	headIndex, err := g.treeIndex(ctx, o.owner, projectID, head)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDiff(base, head, baseIndex, headIndex, func(ref, path string) (string, error) {
+		raw, resp, err := g.sdk(ctx).GetFile(o.owner, g.repoName(projectID), ref, path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read '%s' at '%s': %w", path, ref, giteaError(resp, err))
+		}
+		return string(raw), nil
+	})
+}
+
+// CompareRefs counts the commits head is ahead of and behind base (branches, tags or SHAs) and lists
+// the files that differ between them, e.g. to tell whether a project branch needs a re-sync with the
+// template branch. Both ahead and behind being zero means the refs point at the same commit.
+func (g *GiteaAdapter) CompareRefs(ctx context.Context, projectID uuid.UUID, base, head string, opts ...Option) (*Comparison, error) {
+	g.logger.Info("CompareRefs", "projectID", projectID, "base", base, "head", head)
+	o := g.callOptions(opts)
+
+	ahead, resp, err := g.sdk(ctx).CompareCommits(o.owner, g.repoName(projectID), base, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare '%s' with '%s': %w", head, base, giteaError(resp, err))
+	}
+	behind, resp, err := g.sdk(ctx).CompareCommits(o.owner, g.repoName(projectID), head, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare '%s' with '%s': %w", base, head, giteaError(resp, err))
+	}
+
+	baseIndex, err := g.treeIndex(ctx, o.owner, projectID, base)
+	if err != nil {
+		return nil, err
+	}
+	headIndex, err := g.treeIndex(ctx, o.owner, projectID, head)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comparison{
+		Base:     base,
+		Head:     head,
+		AheadBy:  ahead.TotalCommits,
+		BehindBy: behind.TotalCommits,
+		Files:    compareIndexes(baseIndex, headIndex),
+	}, nil
+}
+
+// BlameFile attributes every line of path at ref (default branch when empty) to the commit
+// that last changed it. The Gitea API has no blame endpoint, so the file is replayed over its
+// history, one request per commit and at most maxBlameCommits of them. Renames are not followed.
+func (g *GiteaAdapter) BlameFile(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) ([]BlameRange, error) {
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+	ref = cmp.Or(ref, g.env.Branch)
+	g.logger.Info("BlameFile", "projectID", projectID, "path", path, "ref", ref)
+	o := g.callOptions(opts)
+
+	var commits []Commit
+	for page := 1; len(commits) < maxBlameCommits; page++ {
+		batch, resp, err := g.sdk(ctx).ListRepoCommits(o.owner, g.repoName(projectID), gitea.ListCommitOptions{
+			ListOptions: gitea.ListOptions{Page: page, PageSize: 50},
+			SHA:         ref,
+			Path:        path,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits of '%s': %w", path, giteaError(resp, err))
+		}
+		for _, c := range batch {
+			commits = append(commits, toCommit(c))
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("failed to blame '%s': %w", path, ErrNotFound)
+	}
+	commits = commits[:min(len(commits), maxBlameCommits)]
+
+	// Replay from the oldest commit; a commit that deleted the file resets it to empty
+	versions := make([]blameVersion, len(commits))
+	for i, c := range commits {
+		raw, resp, err := g.sdk(ctx).GetFile(o.owner, g.repoName(projectID), c.SHA, path)
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return nil, fmt.Errorf("failed to read '%s' at %s: %w", path, c.SHA, giteaError(resp, err))
+		}
+		versions[len(commits)-1-i] = blameVersion{commit: c, content: string(raw)}
+	}
+	return blameRanges(blameHistory(versions)), nil
+}
+
+// RevertCommit undoes the changes sha made, relative to its first parent, in a new commit on the
+// branch. An empty message defaults to "Revert <sha>". It fails with ErrConflict when a later
+// commit has changed one of the same files.
+func (g *GiteaAdapter) RevertCommit(ctx context.Context, projectID uuid.UUID, sha, message string, opts ...Option) error {
+	g.logger.Info("RevertCommit", "projectID", projectID, "sha", sha)
+	o := g.callOptions(opts)
+
+	c, resp, err := g.sdk(ctx).GetSingleCommit(o.owner, g.repoName(projectID), sha)
+	if err != nil {
+		return fmt.Errorf("failed to read commit %s: %w", sha, giteaError(resp, err))
+	}
+	if len(c.Parents) == 0 {
+		return fmt.Errorf("failed to revert %s: root commit", sha)
+	}
+	return revertCommit(ctx, g, g.logger, projectID, c.SHA, c.Parents[0].SHA, message, append(slices.Clone(opts), WithOwner(o.owner)))
+}
+
+// CherryPick applies the changes sha made, relative to its first parent, to targetBranch in a new
+// commit that keeps the original message and notes where it came from. Files changed on the target
+// since are merged line by line; it fails with ErrConflict, committing nothing, when edits overlap.
+func (g *GiteaAdapter) CherryPick(ctx context.Context, projectID uuid.UUID, sha, targetBranch string, opts ...Option) error {
+	g.logger.Info("CherryPick", "projectID", projectID, "sha", sha, "targetBranch", targetBranch)
+	o := g.callOptions(opts)
+
+	c, resp, err := g.sdk(ctx).GetSingleCommit(o.owner, g.repoName(projectID), sha)
+	if err != nil {
+		return fmt.Errorf("failed to read commit %s: %w", sha, giteaError(resp, err))
+	}
+	if len(c.Parents) == 0 {
+		return fmt.Errorf("failed to cherry-pick %s: root commit", sha)
+	}
+	message := ""
+	if c.RepoCommit != nil {
+		message = c.RepoCommit.Message
+	}
+	return cherryPick(ctx, g, g.logger, projectID, c.SHA, c.Parents[0].SHA, message, append(slices.Clone(opts), WithOwner(o.owner), WithBranch(targetBranch)))
+}
+
+// RestoreFile commits the content path had at ref (a branch, tag or commit SHA) onto the branch
+func (g *GiteaAdapter) RestoreFile(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) error {
+	g.logger.Info("RestoreFile", "projectID", projectID, "path", path, "ref", ref)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	return restoreFile(ctx, g, projectID, path, ref, opts)
+}
+
+func toCommit(c *gitea.Commit) Commit {
+	commit := Commit{HTMLURL: c.HTMLURL}
+	if c.CommitMeta != nil {
+		commit.SHA = c.SHA
+		commit.Timestamp = c.Created
+	}
+	if c.RepoCommit != nil {
+		commit.Message = c.RepoCommit.Message
+		commit.Trailers = ParseTrailers(commit.Message)
+		commit.Verified = c.RepoCommit.Verification != nil && c.RepoCommit.Verification.Verified
+		if author := c.RepoCommit.Author; author != nil {
+			commit.AuthorName = author.Name
+			commit.AuthorEmail = author.Email
+			// Prefer the author date over the commit creation time
+			if t, err := time.Parse(time.RFC3339, author.Date); err == nil {
+				commit.Timestamp = t
+			}
+		}
+	}
+	for _, p := range c.Parents {
+		commit.ParentSHAs = append(commit.ParentSHAs, p.SHA)
+	}
+	return commit
+}
+
+// SetCommitStatus reports a check result on sha. Statuses are keyed by Context, so reporting
+// the same context again replaces the previous state in the Gitea UI.
+func (g *GiteaAdapter) SetCommitStatus(ctx context.Context, projectID uuid.UUID, sha string, status CommitStatus, opts ...Option) error {
+	g.logger.Info("SetCommitStatus", "projectID", projectID, "sha", sha, "context", status.Context, "state", status.State)
+	o := g.callOptions(opts)
+
+	if _, resp, err := g.sdk(ctx).CreateStatus(o.owner, g.repoName(projectID), sha, gitea.CreateStatusOption{
+		State:       gitea.StatusState(status.State),
+		TargetURL:   status.TargetURL,
+		Description: status.Description,
+		Context:     status.Context,
+	}); err != nil {
+		return fmt.Errorf("failed to set status '%s' on %s: %w", status.Context, sha, giteaError(resp, err))
+	}
+	return nil
+}
+
+// GetSigningKey returns the armored public GPG key the server signs commits with, so the
+// signatures of commits made without GitConfig.SigningKey can be verified elsewhere. It fails
+// with ErrNotFound when server-side signing is not configured.
+func (g *GiteaAdapter) GetSigningKey(ctx context.Context, projectID uuid.UUID, opts ...Option) (string, error) {
+	g.logger.Info("GetSigningKey", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	resp, err := g.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/signing-key.gpg", o.owner, g.repoName(projectID)), nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get signing key: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := responseError(resp); err != nil {
+		return "", fmt.Errorf("failed to get signing key: %w", err)
+	}
+	key, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read signing key: %w", err)
+	}
+	if len(bytes.TrimSpace(key)) == 0 {
+		return "", fmt.Errorf("failed to get signing key: %w", ErrNotFound)
+	}
+	return string(key), nil
+}
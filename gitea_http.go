@@ -0,0 +1,192 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// newHTTPClient builds the HTTP client described by the timeout, proxy and TLS settings of env
+func newHTTPClient(env *GitConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if env.Proxy != "" {
+		proxy, err := url.Parse(env.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if env.CAFile != "" || env.InsecureSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: env.InsecureSkipVerify}
+		if env.CAFile != "" {
+			pem, err := os.ReadFile(env.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ca file: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("failed to read ca file: no certificates found in %s", env.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{Transport: transport, Timeout: env.Timeout}, nil
+}
+
+// sdk returns an SDK client bound to ctx, so cancelling ctx aborts its requests.
+// It reuses the shared HTTP client and cached server version; creating one sends no request.
+func (g *GiteaAdapter) sdk(ctx context.Context) *gitea.Client {
+	// Options cannot fail: the version was validated in NewGiteaAdapter
+	client, _ := gitea.NewClient(g.env.BaseURL,
+		gitea.SetHTTPClient(g.http),
+		gitea.SetContext(ctx),
+		gitea.SetGiteaVersion(g.version),
+		gitea.SetSudo(sudoUser(ctx)),
+	)
+	return client
+}
+
+// sudoKey is the context key of ContextWithSudo
+type sudoKey struct{}
+
+// ContextWithSudo makes every Gitea API request made with ctx act as username, so the user,
+// not the bot, is the author of commits and the creator of pull requests, issues and comments.
+// The token must belong to a site administrator. Pushes over git, used for signed commits,
+// modes and submodules, still authenticate as the token owner but carry the user as author.
+// The local and memory adapters ignore it; use WithAuthor for attribution everywhere.
+func ContextWithSudo(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, sudoKey{}, username)
+}
+
+// sudoUser returns the user set by ContextWithSudo, empty when there is none
+func sudoUser(ctx context.Context) string {
+	username, _ := ctx.Value(sudoKey{}).(string)
+	return username
+}
+
+// doJSON calls a Gitea API endpoint that the SDK does not wrap.
+// path is relative to /api/v1; body and out are JSON encoded/decoded when non-nil.
+func (g *GiteaAdapter) doJSON(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	header := http.Header{"Accept": {"application/json"}}
+	if body != nil {
+		header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.do(ctx, method, path, reader, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := responseError(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// do sends an authenticated request to path (relative to /api/v1) and returns the response whatever its status.
+// The caller must close the body.
+func (g *GiteaAdapter) do(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(g.env.BaseURL, "/")+"/api/v1"+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if sudo := sudoUser(ctx); sudo != "" {
+		req.Header.Set("Sudo", sudo)
+	}
+
+	return g.http.Do(req)
+}
+
+// responseError reads a non-2xx response into an error tagged with its status sentinel
+func responseError(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	data, _ := io.ReadAll(resp.Body)
+	err := fmt.Errorf("%s %s: %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, strings.TrimSpace(string(data)))
+	return statusError(resp.StatusCode, err)
+}
+
+// escapePath escapes each segment of a repository file path for use in a URL
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// listPages calls fetch for every page of a Gitea listing until the server reports no next
+// page, or only for the page selected by WithPage
+func listPages(o callOptions, fetch func(page gitea.ListOptions) (*gitea.Response, error)) error {
+	if o.page > 0 {
+		_, err := fetch(gitea.ListOptions{Page: o.page, PageSize: o.limit})
+		return err
+	}
+	for page := 1; ; page++ {
+		resp, err := fetch(gitea.ListOptions{Page: page, PageSize: defaultPageSize})
+		if err != nil {
+			return err
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return nil
+		}
+	}
+}
+
+// giteaError tags an SDK error with the sentinel matching the response status
+func giteaError(resp *gitea.Response, err error) error {
+	if err == nil || resp == nil {
+		return err
+	}
+	return statusError(resp.StatusCode, err)
+}
+
+// statusError wraps err with ErrNotFound, ErrConflict, ErrUnauthorized or ErrRateLimited
+// when the HTTP status code maps onto one of them
+func statusError(code int, err error) error {
+	switch code {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// CreateIssue files an issue on the project repository
+func (g *GiteaAdapter) CreateIssue(ctx context.Context, projectID uuid.UUID, title, body string, opts ...Option) (*Issue, error) {
+	g.logger.Info("CreateIssue", "projectID", projectID, "title", title)
+	o := g.callOptions(opts)
+
+	issue, resp, err := g.sdk(ctx).CreateIssue(o.owner, g.repoName(projectID), gitea.CreateIssueOption{
+		Title: title,
+		Body:  body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create issue '%s': %w", title, giteaError(resp, err))
+	}
+	return toIssue(issue), nil
+}
+
+// CommentOnIssue adds a comment to an issue or pull request
+func (g *GiteaAdapter) CommentOnIssue(ctx context.Context, projectID uuid.UUID, index int64, body string, opts ...Option) error {
+	g.logger.Info("CommentOnIssue", "projectID", projectID, "index", index)
+	o := g.callOptions(opts)
+
+	if _, resp, err := g.sdk(ctx).CreateIssueComment(o.owner, g.repoName(projectID), index, gitea.CreateIssueCommentOption{
+		Body: body,
+	}); err != nil {
+		return fmt.Errorf("failed to comment on issue #%d: %w", index, giteaError(resp, err))
+	}
+	return nil
+}
+
+// CloseIssue closes an issue; closing one that is already closed succeeds
+func (g *GiteaAdapter) CloseIssue(ctx context.Context, projectID uuid.UUID, index int64, opts ...Option) error {
+	g.logger.Info("CloseIssue", "projectID", projectID, "index", index)
+	o := g.callOptions(opts)
+
+	state := gitea.StateClosed
+	if _, resp, err := g.sdk(ctx).EditIssue(o.owner, g.repoName(projectID), index, gitea.EditIssueOption{
+		State: &state,
+	}); err != nil {
+		return fmt.Errorf("failed to close issue #%d: %w", index, giteaError(resp, err))
+	}
+	return nil
+}
+
+// ListIssues retrieves issues in state ("open", "closed" or "all"; empty for open), following
+// pagination unless WithPage selects a single page. Pull requests are not included.
+func (g *GiteaAdapter) ListIssues(ctx context.Context, projectID uuid.UUID, state string, opts ...Option) ([]Issue, error) {
+	if state == "" {
+		state = string(gitea.StateOpen)
+	}
+	g.logger.Info("ListIssues", "projectID", projectID, "state", state)
+	o := g.callOptions(opts)
+
+	var issues []Issue
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListRepoIssues(o.owner, g.repoName(projectID), gitea.ListIssueOption{
+			ListOptions: page,
+			State:       gitea.StateType(state),
+			Type:        gitea.IssueTypeIssue,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to list issues: %w", giteaError(resp, err))
+		}
+		for _, i := range batch {
+			issues = append(issues, *toIssue(i))
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+func toIssue(i *gitea.Issue) *Issue {
+	issue := &Issue{
+		Index:     i.Index,
+		Title:     i.Title,
+		Body:      i.Body,
+		State:     string(i.State),
+		HTMLURL:   i.HTMLURL,
+		CreatedAt: i.Created,
+	}
+	for _, l := range i.Labels {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+	return issue
+}
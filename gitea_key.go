@@ -0,0 +1,74 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// AddDeployKey grants the holder of an SSH public key (authorized_keys format) read-only
+// or read-write access to the repository
+func (g *GiteaAdapter) AddDeployKey(ctx context.Context, projectID uuid.UUID, title, publicKey string, readOnly bool, opts ...Option) (*DeployKey, error) {
+	g.logger.Info("AddDeployKey", "projectID", projectID, "title", title, "readOnly", readOnly)
+	o := g.callOptions(opts)
+
+	key, resp, err := g.sdk(ctx).CreateDeployKey(o.owner, g.repoName(projectID), gitea.CreateKeyOption{
+		Title:    title,
+		Key:      strings.TrimSpace(publicKey),
+		ReadOnly: readOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add deploy key '%s': %w", title, giteaError(resp, err))
+	}
+	return toDeployKey(key), nil
+}
+
+// ListDeployKeys retrieves all deploy keys of the repository, following pagination
+// unless WithPage selects a single page
+func (g *GiteaAdapter) ListDeployKeys(ctx context.Context, projectID uuid.UUID, opts ...Option) ([]DeployKey, error) {
+	g.logger.Info("ListDeployKeys", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	var keys []DeployKey
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListDeployKeys(o.owner, g.repoName(projectID), gitea.ListDeployKeysOptions{
+			ListOptions: page,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to list deploy keys: %w", giteaError(resp, err))
+		}
+		for _, k := range batch {
+			keys = append(keys, *toDeployKey(k))
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RemoveDeployKey revokes a deploy key by ID
+func (g *GiteaAdapter) RemoveDeployKey(ctx context.Context, projectID uuid.UUID, id int64, opts ...Option) error {
+	g.logger.Info("RemoveDeployKey", "projectID", projectID, "id", id)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).DeleteDeployKey(o.owner, g.repoName(projectID), id); err != nil {
+		return fmt.Errorf("failed to remove deploy key %d: %w", id, giteaError(resp, err))
+	}
+	return nil
+}
+
+func toDeployKey(k *gitea.DeployKey) *DeployKey {
+	return &DeployKey{
+		ID:          k.ID,
+		Title:       k.Title,
+		Key:         k.Key,
+		Fingerprint: k.Fingerprint,
+		ReadOnly:    k.ReadOnly,
+		CreatedAt:   k.Created,
+	}
+}
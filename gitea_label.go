@@ -0,0 +1,200 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// EnsureLabel creates label (Color as "#rrggbb") unless the repository already has a label with
+// the same name, in which case the existing one is returned unchanged
+func (g *GiteaAdapter) EnsureLabel(ctx context.Context, projectID uuid.UUID, label Label, opts ...Option) (*Label, error) {
+	g.logger.Info("EnsureLabel", "projectID", projectID, "name", label.Name)
+	o := g.callOptions(opts)
+
+	labels, err := g.ListLabels(ctx, projectID, WithOwner(o.owner))
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range labels {
+		if l.Name == label.Name {
+			return &l, nil
+		}
+	}
+
+	created, resp, err := g.sdk(ctx).CreateLabel(o.owner, g.repoName(projectID), gitea.CreateLabelOption{
+		Name:        label.Name,
+		Color:       label.Color,
+		Description: label.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create label '%s': %w", label.Name, giteaError(resp, err))
+	}
+	return toLabel(created), nil
+}
+
+// ListLabels retrieves all labels of the repository, following pagination
+// unless WithPage selects a single page
+func (g *GiteaAdapter) ListLabels(ctx context.Context, projectID uuid.UUID, opts ...Option) ([]Label, error) {
+	g.logger.Info("ListLabels", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	var labels []Label
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListRepoLabels(o.owner, g.repoName(projectID), gitea.ListLabelsOptions{
+			ListOptions: page,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to list labels: %w", giteaError(resp, err))
+		}
+		for _, l := range batch {
+			labels = append(labels, *toLabel(l))
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// AddLabels applies labels by name to an issue or pull request. Every label must exist, see EnsureLabel.
+func (g *GiteaAdapter) AddLabels(ctx context.Context, projectID uuid.UUID, index int64, names []string, opts ...Option) error {
+	g.logger.Info("AddLabels", "projectID", projectID, "index", index, "labels", names)
+	o := g.callOptions(opts)
+
+	ids, err := g.labelIDs(ctx, o.owner, projectID, names)
+	if err != nil {
+		return err
+	}
+	if _, resp, err := g.sdk(ctx).AddIssueLabels(o.owner, g.repoName(projectID), index, gitea.IssueLabelsOption{
+		Labels: ids,
+	}); err != nil {
+		return fmt.Errorf("failed to add labels to #%d: %w", index, giteaError(resp, err))
+	}
+	return nil
+}
+
+// RemoveLabel removes a label by name from an issue or pull request
+func (g *GiteaAdapter) RemoveLabel(ctx context.Context, projectID uuid.UUID, index int64, name string, opts ...Option) error {
+	g.logger.Info("RemoveLabel", "projectID", projectID, "index", index, "label", name)
+	o := g.callOptions(opts)
+
+	ids, err := g.labelIDs(ctx, o.owner, projectID, []string{name})
+	if err != nil {
+		return err
+	}
+	if resp, err := g.sdk(ctx).DeleteIssueLabel(o.owner, g.repoName(projectID), index, ids[0]); err != nil {
+		return fmt.Errorf("failed to remove label '%s' from #%d: %w", name, index, giteaError(resp, err))
+	}
+	return nil
+}
+
+// EnsureMilestone creates an open milestone unless one with the same title exists. A nil due leaves it without a deadline.
+func (g *GiteaAdapter) EnsureMilestone(ctx context.Context, projectID uuid.UUID, title, description string, due *time.Time, opts ...Option) (*Milestone, error) {
+	g.logger.Info("EnsureMilestone", "projectID", projectID, "title", title)
+	o := g.callOptions(opts)
+
+	milestone, err := g.milestone(ctx, o.owner, projectID, title)
+	if err == nil {
+		return milestone, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	created, resp, err := g.sdk(ctx).CreateMilestone(o.owner, g.repoName(projectID), gitea.CreateMilestoneOption{
+		Title:       title,
+		Description: description,
+		State:       gitea.StateOpen,
+		Deadline:    due,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create milestone '%s': %w", title, giteaError(resp, err))
+	}
+	return toMilestone(created), nil
+}
+
+// SetMilestone assigns an issue or pull request to the milestone titled title; an empty title clears it
+func (g *GiteaAdapter) SetMilestone(ctx context.Context, projectID uuid.UUID, index int64, title string, opts ...Option) error {
+	g.logger.Info("SetMilestone", "projectID", projectID, "index", index, "milestone", title)
+	o := g.callOptions(opts)
+
+	var id int64
+	if title != "" {
+		milestone, err := g.milestone(ctx, o.owner, projectID, title)
+		if err != nil {
+			return err
+		}
+		id = milestone.ID
+	}
+	if _, resp, err := g.sdk(ctx).EditIssue(o.owner, g.repoName(projectID), index, gitea.EditIssueOption{
+		Milestone: &id,
+	}); err != nil {
+		return fmt.Errorf("failed to set milestone of #%d: %w", index, giteaError(resp, err))
+	}
+	return nil
+}
+
+// labelIDs resolves label names to IDs, failing with ErrNotFound for unknown names
+func (g *GiteaAdapter) labelIDs(ctx context.Context, owner string, projectID uuid.UUID, names []string) ([]int64, error) {
+	labels, err := g.ListLabels(ctx, projectID, WithOwner(owner))
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]int64, len(labels))
+	for _, l := range labels {
+		byName[l.Name] = l.ID
+	}
+
+	ids := make([]int64, 0, len(names))
+	for _, name := range names {
+		id, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("failed to resolve label '%s': %w", name, ErrNotFound)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// milestone finds a milestone by exact title in any state
+func (g *GiteaAdapter) milestone(ctx context.Context, owner string, projectID uuid.UUID, title string) (*Milestone, error) {
+	for page := 1; ; page++ {
+		batch, resp, err := g.sdk(ctx).ListRepoMilestones(owner, g.repoName(projectID), gitea.ListMilestoneOption{
+			ListOptions: gitea.ListOptions{Page: page, PageSize: 50},
+			State:       gitea.StateAll,
+			Name:        title,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list milestones: %w", giteaError(resp, err))
+		}
+		for _, m := range batch {
+			if m.Title == title {
+				return toMilestone(m), nil
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return nil, fmt.Errorf("failed to find milestone '%s': %w", title, ErrNotFound)
+		}
+	}
+}
+
+func toLabel(l *gitea.Label) *Label {
+	return &Label{ID: l.ID, Name: l.Name, Color: "#" + strings.TrimPrefix(l.Color, "#"), Description: l.Description}
+}
+
+func toMilestone(m *gitea.Milestone) *Milestone {
+	return &Milestone{
+		ID:          m.ID,
+		Title:       m.Title,
+		Description: m.Description,
+		State:       string(m.State),
+		DueAt:       m.Deadline,
+	}
+}
@@ -0,0 +1,224 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	lfsMediaType      = "application/vnd.git-lfs+json"
+	gitattributesPath = ".gitattributes"
+)
+
+type (
+	// lfsBatchRequest and lfsBatchResponse follow the Git LFS batch API
+	lfsBatchRequest struct {
+		Operation string      `json:"operation"`
+		Transfers []string    `json:"transfers"`
+		Objects   []lfsObject `json:"objects"`
+	}
+
+	lfsBatchResponse struct {
+		Objects []struct {
+			lfsObject
+			Actions map[string]lfsAction `json:"actions"`
+			Error   *struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"objects"`
+	}
+
+	lfsObject struct {
+		OID  string `json:"oid"`
+		Size int64  `json:"size"`
+	}
+
+	lfsAction struct {
+		Href   string            `json:"href"`
+		Header map[string]string `json:"header"`
+	}
+)
+
+// resolveLFS swaps the pointer content of node for the LFS object when WithResolveLFS is set.
+// node itself is not modified since it may be cached.
+func (g *GiteaAdapter) resolveLFS(ctx context.Context, o callOptions, projectID uuid.UUID, node *FileNode) (*FileNode, error) {
+	if !o.resolveLFS || node.LFS == nil {
+		return node, nil
+	}
+
+	raw, resp, err := g.sdk(ctx).GetFile(o.owner, g.repoName(projectID), o.branch, node.Path, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LFS object of '%s': %w", node.Path, giteaError(resp, err))
+	}
+	resolved := *node
+	resolved.setContent(raw)
+	resolved.LFS = node.LFS
+	return &resolved, nil
+}
+
+// CommitLFSFile uploads the content of r to the repository's Git LFS store and commits a pointer
+// to it at path, adding path to .gitattributes in the same commit when no rule covers it yet.
+// The content is spooled to a temporary file to compute its SHA-256 before the upload.
+func (g *GiteaAdapter) CommitLFSFile(ctx context.Context, projectID uuid.UUID, path string, r io.Reader, message string, opts ...Option) error {
+	g.logger.Info("CommitLFSFile", "projectID", projectID, "path", path, "message", message)
+	o := g.callOptions(opts)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "git-lfs-*")
+	if err != nil {
+		return fmt.Errorf("failed to buffer LFS object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if err != nil {
+		return fmt.Errorf("failed to buffer LFS object: %w", err)
+	}
+	pointer := LFSPointer{OID: hex.EncodeToString(h.Sum(nil)), Size: size}
+
+	if err := g.uploadLFS(ctx, o.owner, projectID, pointer, tmp); err != nil {
+		return err
+	}
+
+	changes := []FileChange{{Path: path, Content: pointer.String()}}
+	attributes, err := g.GetFile(ctx, projectID, gitattributesPath, opts...)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to read %s: %w", gitattributesPath, err)
+	}
+	current := ""
+	if attributes != nil {
+		current = string(attributes.Data())
+	}
+	if !hasLFSRule(current, path) {
+		rule := strings.ReplaceAll(path, " ", "[[:space:]]") + " filter=lfs diff=lfs merge=lfs -text\n"
+		if current != "" && !strings.HasSuffix(current, "\n") {
+			current += "\n"
+		}
+		changes = append(changes, FileChange{Path: gitattributesPath, Content: current + rule})
+	}
+	return g.CommitFiles(ctx, projectID, changes, message, opts...)
+}
+
+// uploadLFS sends the object in f through the batch API unless the server already has it
+func (g *GiteaAdapter) uploadLFS(ctx context.Context, owner string, projectID uuid.UUID, pointer LFSPointer, f *os.File) error {
+	batchURL := fmt.Sprintf("%s/%s/%s.git/info/lfs/objects/batch", strings.TrimRight(g.env.BaseURL, "/"), owner, g.repoName(projectID))
+	var batch lfsBatchResponse
+	err := g.doLFS(ctx, http.MethodPost, batchURL, nil, lfsBatchRequest{
+		Operation: "upload",
+		Transfers: []string{"basic"},
+		Objects:   []lfsObject{{OID: pointer.OID, Size: pointer.Size}},
+	}, &batch)
+	if err != nil {
+		return fmt.Errorf("failed to request LFS upload: %w", err)
+	}
+	if len(batch.Objects) != 1 {
+		return fmt.Errorf("failed to request LFS upload: %d objects in response", len(batch.Objects))
+	}
+	object := batch.Objects[0]
+	if object.Error != nil {
+		return fmt.Errorf("failed to request LFS upload: %w", statusError(object.Error.Code, errors.New(object.Error.Message)))
+	}
+
+	upload, ok := object.Actions["upload"]
+	if !ok {
+		g.logger.Debug("LFS object already stored", "projectID", projectID, "oid", pointer.OID)
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, upload.Href, io.NewSectionReader(f, 0, pointer.Size))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = pointer.Size
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(io.NewSectionReader(f, 0, pointer.Size)), nil
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	g.setLFSAuth(req, upload.Header)
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload LFS object: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := responseError(resp); err != nil {
+		return fmt.Errorf("failed to upload LFS object: %w", err)
+	}
+
+	if verify, ok := object.Actions["verify"]; ok {
+		if err := g.doLFS(ctx, http.MethodPost, verify.Href, verify.Header, lfsObject{OID: pointer.OID, Size: pointer.Size}, nil); err != nil {
+			return fmt.Errorf("failed to verify LFS object: %w", err)
+		}
+	}
+	return nil
+}
+
+// doLFS sends a JSON request to an LFS endpoint, decoding the response into out when non-nil
+func (g *GiteaAdapter) doLFS(ctx context.Context, method, url string, header map[string]string, body, out any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request body: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	g.setLFSAuth(req, header)
+
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := responseError(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// setLFSAuth applies the headers an action came with; without them, requests to the Gitea host
+// get the owner's access token from the transport
+func (g *GiteaAdapter) setLFSAuth(req *http.Request, header map[string]string) {
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+}
+
+// hasLFSRule reports whether .gitattributes content routes path through the LFS filter.
+// Only exact paths and "*.ext" patterns are recognized; anything else gets its own rule.
+func hasLFSRule(attributes, path string) bool {
+	for _, line := range strings.Split(attributes, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.Contains(line, "filter=lfs") {
+			continue
+		}
+		pattern := fields[0]
+		if pattern == path || pattern == "/"+path {
+			return true
+		}
+		if ext, ok := strings.CutPrefix(pattern, "*."); ok && !strings.Contains(ext, "/") && strings.HasSuffix(path, "."+ext) {
+			return true
+		}
+	}
+	return false
+}
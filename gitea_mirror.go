@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// pushMirrorInterval is how often Gitea pushes to a mirror in addition to every commit
+const pushMirrorInterval = "8h0m0s"
+
+// ConfigurePushMirror mirrors the repository to remoteURL (e.g. a GitHub or GitLab HTTPS clone URL)
+// on every commit and periodically. An existing mirror to the same URL is replaced, so the call
+// can be repeated to rotate credentials.
+func (g *GiteaAdapter) ConfigurePushMirror(ctx context.Context, projectID uuid.UUID, remoteURL string, credentials MirrorCredentials, opts ...Option) (*PushMirror, error) {
+	g.logger.Info("ConfigurePushMirror", "projectID", projectID, "remote", remoteURL)
+	o := g.callOptions(opts)
+
+	mirrors, err := g.ListPushMirrors(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mirrors {
+		if m.RemoteURL == remoteURL {
+			if err := g.RemovePushMirror(ctx, projectID, m.RemoteName); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	mirror, resp, err := g.sdk(ctx).PushMirrors(o.owner, g.repoName(projectID), gitea.CreatePushMirrorOption{
+		RemoteAddress:  remoteURL,
+		RemoteUsername: credentials.Username,
+		RemotePassword: credentials.Password,
+		Interval:       pushMirrorInterval,
+		SyncONCommit:   true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure push mirror: %w", giteaError(resp, err))
+	}
+	return toPushMirror(mirror), nil
+}
+
+// ListPushMirrors retrieves all push mirrors of the repository, following pagination
+// unless WithPage selects a single page
+func (g *GiteaAdapter) ListPushMirrors(ctx context.Context, projectID uuid.UUID, opts ...Option) ([]PushMirror, error) {
+	g.logger.Info("ListPushMirrors", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	var mirrors []PushMirror
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListPushMirrors(o.owner, g.repoName(projectID), page)
+		if err != nil {
+			return resp, fmt.Errorf("failed to list push mirrors: %w", giteaError(resp, err))
+		}
+		for _, m := range batch {
+			mirrors = append(mirrors, *toPushMirror(m))
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mirrors, nil
+}
+
+// RemovePushMirror stops mirroring to the remote named remoteName
+func (g *GiteaAdapter) RemovePushMirror(ctx context.Context, projectID uuid.UUID, remoteName string, opts ...Option) error {
+	g.logger.Info("RemovePushMirror", "projectID", projectID, "remote", remoteName)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).DeletePushMirror(o.owner, g.repoName(projectID), remoteName); err != nil {
+		return fmt.Errorf("failed to remove push mirror '%s': %w", remoteName, giteaError(resp, err))
+	}
+	return nil
+}
+
+func toPushMirror(m *gitea.PushMirrorResponse) *PushMirror {
+	return &PushMirror{
+		RemoteName:   m.RemoteName,
+		RemoteURL:    m.RemoteAddress,
+		Interval:     m.Interval,
+		SyncOnCommit: m.SyncONCommit,
+		LastUpdate:   m.LastUpdate,
+		LastError:    m.LastError,
+	}
+}
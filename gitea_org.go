@@ -0,0 +1,40 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// EnsureOrganization creates the organization name when it does not exist yet, so repositories
+// can be created in it with WithOwner. Its visibility follows CreateRepoPrivate.
+func (g *GiteaAdapter) EnsureOrganization(ctx context.Context, name string) error {
+	g.logger.Info("EnsureOrganization", "name", name)
+
+	_, resp, err := g.sdk(ctx).GetOrg(name)
+	if err == nil {
+		return nil
+	}
+	if err = giteaError(resp, err); !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to get organization '%s': %w", name, err)
+	}
+
+	visibility := gitea.VisibleTypePublic
+	if g.env.CreateRepoPrivate {
+		visibility = gitea.VisibleTypePrivate
+	}
+	_, resp, err = g.sdk(ctx).CreateOrg(gitea.CreateOrgOption{
+		Name:       name,
+		Visibility: visibility,
+	})
+	if err != nil {
+		// Lost a race with a concurrent create
+		if err = giteaError(resp, err); errors.Is(err, ErrConflict) {
+			return nil
+		}
+		return fmt.Errorf("failed to create organization '%s': %w", name, err)
+	}
+	return nil
+}
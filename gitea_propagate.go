@@ -0,0 +1,98 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// PropagateChange applies the changes staged in change to every project in projectIDs through a pool
+// of opts.Workers goroutines, each as a single commit, or as a pull request when opts.PullRequest is
+// set. The project change was staged for is ignored. Every project is attempted; the returned error
+// joins the per-project failures so callers can retry PropagateResult.Failed.
+func (g *GiteaAdapter) PropagateChange(ctx context.Context, projectIDs []uuid.UUID, change *ChangeSet, opts PropagateOptions) (*PropagateResult, error) {
+	workers := max(opts.Workers, 1)
+	g.logger.Info("PropagateChange", "projects", len(projectIDs), "changes", change.Len(), "workers", workers, "pullRequest", opts.PullRequest)
+	if err := change.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid change set: %w", err)
+	}
+	if opts.Message == "" {
+		return nil, errors.New("failed to propagate change: empty commit message")
+	}
+	if opts.PullRequest && opts.HeadBranch == "" {
+		return nil, errors.New("failed to propagate change: HeadBranch is required with PullRequest")
+	}
+
+	errs := make([]error, len(projectIDs))
+	prs := make([]*PullRequest, len(projectIDs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				prs[i], errs[i] = g.propagateTo(ctx, projectIDs[i], change, opts)
+			}
+		}()
+	}
+
+dispatch:
+	for i := range projectIDs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			for j := i; j < len(projectIDs); j++ {
+				errs[j] = ctx.Err()
+			}
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := &PropagateResult{Errors: map[uuid.UUID]error{}}
+	var failed []error
+	for i, projectID := range projectIDs {
+		if errs[i] != nil {
+			g.logger.Error("Propagation failed", "projectID", projectID, "err", errs[i])
+			result.Failed = append(result.Failed, projectID)
+			result.Errors[projectID] = errs[i]
+			failed = append(failed, fmt.Errorf("project %s: %w", projectID, errs[i]))
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, projectID)
+		if prs[i] != nil {
+			if result.PullRequests == nil {
+				result.PullRequests = map[uuid.UUID]*PullRequest{}
+			}
+			result.PullRequests[projectID] = prs[i]
+		}
+	}
+	g.logger.Info("Propagated change", "succeeded", len(result.Succeeded), "failed", len(result.Failed))
+	return result, errors.Join(failed...)
+}
+
+// propagateTo commits change to one project, on a new branch with a pull request when asked to
+func (g *GiteaAdapter) propagateTo(ctx context.Context, projectID uuid.UUID, change *ChangeSet, opts PropagateOptions) (*PullRequest, error) {
+	cs := newChangeSet(g, projectID)
+	cs.changes, cs.errs = change.Changes(), nil
+	base := cmp.Or(opts.BaseBranch, g.env.Branch)
+
+	if !opts.PullRequest {
+		return nil, cs.Commit(ctx, opts.Message, append(slices.Clone(opts.Options), WithBranch(base))...)
+	}
+
+	if _, err := g.CreateBranch(ctx, projectID, opts.HeadBranch, base, opts.Options...); err != nil {
+		return nil, err
+	}
+	if err := cs.Commit(ctx, opts.Message, append(slices.Clone(opts.Options), WithBranch(opts.HeadBranch))...); err != nil {
+		return nil, err
+	}
+	return g.CreatePullRequest(ctx, projectID, opts.HeadBranch, base, cmp.Or(opts.Title, opts.Message), opts.Body, opts.Options...)
+}
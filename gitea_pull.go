@@ -0,0 +1,366 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// CreatePullRequest opens a pull request merging head into base. An empty base uses the configured branch.
+func (g *GiteaAdapter) CreatePullRequest(ctx context.Context, projectID uuid.UUID, head, base, title, body string, opts ...Option) (*PullRequest, error) {
+	o := g.callOptions(opts)
+	if base == "" {
+		base = o.branch
+	}
+	g.logger.Info("CreatePullRequest", "projectID", projectID, "head", head, "base", base, "title", title)
+
+	pr, resp, err := g.sdk(ctx).CreatePullRequest(o.owner, g.repoName(projectID), gitea.CreatePullRequestOption{
+		Head:  head,
+		Base:  base,
+		Title: title,
+		Body:  body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request %s -> %s: %w", head, base, giteaError(resp, err))
+	}
+	return toPullRequest(pr), nil
+}
+
+// MergePullRequest merges a pull request using the given strategy. It fails with ErrConflict when
+// the pull request cannot be merged, e.g. because of conflicts or pending checks.
+func (g *GiteaAdapter) MergePullRequest(ctx context.Context, projectID uuid.UUID, index int64, strategy MergeStrategy, opts ...Option) error {
+	g.logger.Info("MergePullRequest", "projectID", projectID, "index", index, "strategy", strategy)
+	o := g.callOptions(opts)
+
+	_, resp, err := g.sdk(ctx).MergePullRequest(o.owner, g.repoName(projectID), index, gitea.MergePullRequestOption{
+		Style: gitea.MergeStyle(strategy),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to merge pull request #%d: %w", index, giteaError(resp, err))
+	}
+	// The SDK reports every status other than 200 as not merged without an error
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusMethodNotAllowed, http.StatusConflict:
+		return fmt.Errorf("failed to merge pull request #%d: %s: %w", index, resp.Status, ErrConflict)
+	}
+	return fmt.Errorf("failed to merge pull request #%d: %w", index, statusError(resp.StatusCode, errors.New(resp.Status)))
+}
+
+func toPullRequest(pr *gitea.PullRequest) *PullRequest {
+	out := &PullRequest{
+		Index:   pr.Index,
+		Title:   pr.Title,
+		Body:    pr.Body,
+		State:   string(pr.State),
+		Merged:  pr.HasMerged,
+		HTMLURL: pr.HTMLURL,
+	}
+	if pr.Head != nil {
+		out.Head = pr.Head.Ref
+	}
+	if pr.Base != nil {
+		out.Base = pr.Base.Ref
+	}
+	if pr.MergedCommitID != nil {
+		out.MergeCommitSHA = *pr.MergedCommitID
+	}
+	return out
+}
+
+// RequestReviewers asks users and teams to review a pull request
+func (g *GiteaAdapter) RequestReviewers(ctx context.Context, projectID uuid.UUID, index int64, reviewers, teams []string, opts ...Option) error {
+	g.logger.Info("RequestReviewers", "projectID", projectID, "index", index, "reviewers", reviewers, "teams", teams)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).CreateReviewRequests(o.owner, g.repoName(projectID), index, gitea.PullReviewRequestOptions{
+		Reviewers:     reviewers,
+		TeamReviewers: teams,
+	}); err != nil {
+		return fmt.Errorf("failed to request reviewers on pull request #%d: %w", index, giteaError(resp, err))
+	}
+	return nil
+}
+
+// SubmitReview approves, comments on or requests changes to a pull request. With WithExpectedSHA it
+// fails with ErrConflict unless sha is still the head commit. Gitea rejects reviews of one's own pull requests.
+func (g *GiteaAdapter) SubmitReview(ctx context.Context, projectID uuid.UUID, index int64, event ReviewEvent, body string, opts ...Option) (*Review, error) {
+	g.logger.Info("SubmitReview", "projectID", projectID, "index", index, "event", event)
+	o := g.callOptions(opts)
+
+	if o.expectedSHA != "" {
+		pr, resp, err := g.sdk(ctx).GetPullRequest(o.owner, g.repoName(projectID), index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request #%d: %w", index, giteaError(resp, err))
+		}
+		if pr.Head == nil || pr.Head.Sha != o.expectedSHA {
+			return nil, fmt.Errorf("failed to review pull request #%d: head is no longer %s: %w", index, o.expectedSHA, ErrConflict)
+		}
+	}
+
+	review, resp, err := g.sdk(ctx).CreatePullReview(o.owner, g.repoName(projectID), index, gitea.CreatePullReviewOptions{
+		State:    gitea.ReviewStateType(event),
+		Body:     body,
+		CommitID: o.expectedSHA,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review pull request #%d: %w", index, giteaError(resp, err))
+	}
+	return toReview(review), nil
+}
+
+// ListReviews returns the reviews of a pull request, oldest first
+func (g *GiteaAdapter) ListReviews(ctx context.Context, projectID uuid.UUID, index int64, opts ...Option) ([]Review, error) {
+	g.logger.Info("ListReviews", "projectID", projectID, "index", index)
+	o := g.callOptions(opts)
+
+	var reviews []Review
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListPullReviews(o.owner, g.repoName(projectID), index, gitea.ListPullReviewsOptions{
+			ListOptions: page,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to list reviews of pull request #%d: %w", index, giteaError(resp, err))
+		}
+		for _, r := range batch {
+			reviews = append(reviews, *toReview(r))
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// DismissReview dismisses a review so it no longer counts towards or blocks merging
+func (g *GiteaAdapter) DismissReview(ctx context.Context, projectID uuid.UUID, index, reviewID int64, message string, opts ...Option) error {
+	g.logger.Info("DismissReview", "projectID", projectID, "index", index, "reviewID", reviewID)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).DismissPullReview(o.owner, g.repoName(projectID), index, reviewID, gitea.DismissPullReviewOptions{
+		Message: message,
+	}); err != nil {
+		return fmt.Errorf("failed to dismiss review %d of pull request #%d: %w", reviewID, index, giteaError(resp, err))
+	}
+	return nil
+}
+
+func toReview(r *gitea.PullReview) *Review {
+	out := &Review{
+		ID:          r.ID,
+		State:       ReviewEvent(r.State),
+		Body:        r.Body,
+		CommitSHA:   r.CommitID,
+		Stale:       r.Stale,
+		Official:    r.Official,
+		Dismissed:   r.Dismissed,
+		SubmittedAt: r.Submitted,
+		HTMLURL:     r.HTMLURL,
+	}
+	if r.Reviewer != nil {
+		out.Reviewer = r.Reviewer.UserName
+	} else if r.ReviewerTeam != nil {
+		out.Reviewer = r.ReviewerTeam.Name
+	}
+	return out
+}
+
+// EnableAutoMerge schedules a pull request to merge with strategy once its required status checks
+// succeed, or merges it right away when they already have. With WithExpectedSHA Gitea refuses the
+// merge unless sha is still the head commit.
+func (g *GiteaAdapter) EnableAutoMerge(ctx context.Context, projectID uuid.UUID, index int64, strategy MergeStrategy, opts ...Option) error {
+	g.logger.Info("EnableAutoMerge", "projectID", projectID, "index", index, "strategy", strategy)
+	o := g.callOptions(opts)
+
+	_, resp, err := g.sdk(ctx).MergePullRequest(o.owner, g.repoName(projectID), index, gitea.MergePullRequestOption{
+		Style:                  gitea.MergeStyle(strategy),
+		HeadCommitId:           o.expectedSHA,
+		MergeWhenChecksSucceed: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable auto-merge on pull request #%d: %w", index, giteaError(resp, err))
+	}
+	// Gitea answers 201 when it scheduled the merge and 200 when it merged straight away
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusOK:
+		g.logger.Info("Checks already passed, merged", "projectID", projectID, "index", index)
+	default:
+		return fmt.Errorf("failed to enable auto-merge on pull request #%d: %w", index, statusError(resp.StatusCode, errors.New(resp.Status)))
+	}
+	return nil
+}
+
+// DisableAutoMerge cancels the merge EnableAutoMerge scheduled
+func (g *GiteaAdapter) DisableAutoMerge(ctx context.Context, projectID uuid.UUID, index int64, opts ...Option) error {
+	g.logger.Info("DisableAutoMerge", "projectID", projectID, "index", index)
+	o := g.callOptions(opts)
+
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/merge", o.owner, g.repoName(projectID), index)
+	if err := g.doJSON(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("failed to disable auto-merge on pull request #%d: %w", index, err)
+	}
+	return nil
+}
+
+// PostComment adds a comment to the conversation of a pull request
+func (g *GiteaAdapter) PostComment(ctx context.Context, projectID uuid.UUID, index int64, body string, opts ...Option) (*PullComment, error) {
+	g.logger.Info("PostComment", "projectID", projectID, "index", index)
+	o := g.callOptions(opts)
+
+	comment, resp, err := g.sdk(ctx).CreateIssueComment(o.owner, g.repoName(projectID), index, gitea.CreateIssueCommentOption{
+		Body: body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to comment on pull request #%d: %w", index, giteaError(resp, err))
+	}
+	return toPullComment(comment), nil
+}
+
+// PostLineComments anchors comments to lines of the files a pull request changes, e.g. where a
+// generated file fails policy. They are submitted together as a comment review with body as its
+// summary. With WithExpectedSHA they are made on commit sha instead of the current head.
+func (g *GiteaAdapter) PostLineComments(ctx context.Context, projectID uuid.UUID, index int64, body string, comments []LineComment, opts ...Option) ([]PullComment, error) {
+	g.logger.Info("PostLineComments", "projectID", projectID, "index", index, "comments", len(comments))
+	o := g.callOptions(opts)
+
+	paths := make([]string, len(comments))
+	opt := gitea.CreatePullReviewOptions{
+		State:    gitea.ReviewStateComment,
+		Body:     body,
+		CommitID: o.expectedSHA,
+	}
+	for i, c := range comments {
+		paths[i] = c.Path
+		opt.Comments = append(opt.Comments, gitea.CreatePullReviewComment{Path: c.Path, Body: c.Body, NewLineNum: c.Line})
+	}
+	if err := validatePaths(paths...); err != nil {
+		return nil, err
+	}
+
+	review, resp, err := g.sdk(ctx).CreatePullReview(o.owner, g.repoName(projectID), index, opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to comment on pull request #%d: %w", index, giteaError(resp, err))
+	}
+	return g.reviewComments(ctx, o, projectID, index, review.ID)
+}
+
+// ListComments returns the conversation comments of a pull request followed by its line-anchored ones
+func (g *GiteaAdapter) ListComments(ctx context.Context, projectID uuid.UUID, index int64, opts ...Option) ([]PullComment, error) {
+	g.logger.Info("ListComments", "projectID", projectID, "index", index)
+	o := g.callOptions(opts)
+
+	var comments []PullComment
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListIssueComments(o.owner, g.repoName(projectID), index, gitea.ListIssueCommentOptions{
+			ListOptions: page,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to list comments of pull request #%d: %w", index, giteaError(resp, err))
+		}
+		for _, c := range batch {
+			comments = append(comments, *toPullComment(c))
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := g.ListReviews(ctx, projectID, index, WithOwner(o.owner))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reviews {
+		batch, err := g.reviewComments(ctx, o, projectID, index, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, batch...)
+	}
+	return comments, nil
+}
+
+// UpdateComment replaces the body of a conversation or line-anchored comment, e.g. to report that
+// a flagged file passes policy now
+func (g *GiteaAdapter) UpdateComment(ctx context.Context, projectID uuid.UUID, commentID int64, body string, opts ...Option) (*PullComment, error) {
+	g.logger.Info("UpdateComment", "projectID", projectID, "commentID", commentID)
+	o := g.callOptions(opts)
+
+	comment, resp, err := g.sdk(ctx).EditIssueComment(o.owner, g.repoName(projectID), commentID, gitea.EditIssueCommentOption{
+		Body: body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment %d: %w", commentID, giteaError(resp, err))
+	}
+	return toPullComment(comment), nil
+}
+
+// DeleteComment removes a conversation or line-anchored comment. Gitea's API cannot resolve
+// conversations (see ResolveComment), so automated annotations are retracted this way once addressed.
+func (g *GiteaAdapter) DeleteComment(ctx context.Context, projectID uuid.UUID, commentID int64, opts ...Option) error {
+	g.logger.Info("DeleteComment", "projectID", projectID, "commentID", commentID)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).DeleteIssueComment(o.owner, g.repoName(projectID), commentID); err != nil {
+		return fmt.Errorf("failed to delete comment %d: %w", commentID, giteaError(resp, err))
+	}
+	return nil
+}
+
+// ResolveComment would mark the conversation of a line-anchored comment as resolved. Gitea only
+// resolves conversations from its web UI, not its API, so it always fails with ErrUnsupported;
+// retract the comment with DeleteComment or reply with UpdateComment instead.
+func (g *GiteaAdapter) ResolveComment(ctx context.Context, projectID uuid.UUID, commentID int64, opts ...Option) error {
+	g.logger.Info("ResolveComment", "projectID", projectID, "commentID", commentID)
+
+	return fmt.Errorf("failed to resolve comment %d: Gitea's API cannot resolve conversations: %w", commentID, ErrUnsupported)
+}
+
+// reviewComments returns the line-anchored comments of a review
+func (g *GiteaAdapter) reviewComments(ctx context.Context, o callOptions, projectID uuid.UUID, index, reviewID int64) ([]PullComment, error) {
+	batch, resp, err := g.sdk(ctx).ListPullReviewComments(o.owner, g.repoName(projectID), index, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments of review %d: %w", reviewID, giteaError(resp, err))
+	}
+	comments := make([]PullComment, 0, len(batch))
+	for _, c := range batch {
+		comment := PullComment{
+			ID:        c.ID,
+			ReviewID:  c.ReviewID,
+			Body:      c.Body,
+			Path:      c.Path,
+			Line:      int64(c.LineNum),
+			CommitSHA: c.CommitID,
+			Resolved:  c.Resolver != nil,
+			HTMLURL:   c.HTMLURL,
+			CreatedAt: c.Created,
+			UpdatedAt: c.Updated,
+		}
+		if c.Reviewer != nil {
+			comment.Author = c.Reviewer.UserName
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+func toPullComment(c *gitea.Comment) *PullComment {
+	out := &PullComment{
+		ID:        c.ID,
+		Body:      c.Body,
+		HTMLURL:   c.HTMLURL,
+		CreatedAt: c.Created,
+		UpdatedAt: c.Updated,
+	}
+	if c.Poster != nil {
+		out.Author = c.Poster.UserName
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/uuid"
+)
+
+// pushChanges commits the changes edit computes on top of the branch tip and pushes the commit
+// over git's smart HTTP protocol. The contents API cannot write tree entry modes, so gitlinks,
+// symlinks, executable bits and signed commits go through here. Only the tip commit is fetched, into memory.
+func (g *GiteaAdapter) pushChanges(ctx context.Context, projectID uuid.UUID, o callOptions, message string, edit treeEdit) error {
+	owner, branch := o.owner, o.branch
+	author, err := g.author(ctx, o)
+	if err != nil {
+		return err
+	}
+	remote := fmt.Sprintf("%s/%s/%s.git", strings.TrimRight(g.env.BaseURL, "/"), owner, g.repoName(projectID))
+	refName := plumbing.NewBranchReferenceName(branch)
+
+	// Gitea accepts the access token as the basic auth password for any user name
+	token, err := g.creds.Token(ctx, owner)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+	auth := &githttp.BasicAuth{Username: owner, Password: token}
+	var caBundle []byte
+	if g.env.CAFile != "" {
+		if caBundle, err = os.ReadFile(g.env.CAFile); err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+	}
+	proxy := transport.ProxyOptions{URL: g.env.Proxy}
+
+	repo, err := gogit.CloneContext(ctx, memory.NewStorage(), nil, &gogit.CloneOptions{
+		URL:             remote,
+		Auth:            auth,
+		ReferenceName:   refName,
+		SingleBranch:    true,
+		Depth:           1,
+		NoCheckout:      true,
+		InsecureSkipTLS: g.env.InsecureSkipVerify,
+		CABundle:        caBundle,
+		ProxyOptions:    proxy,
+	})
+	var root *object.Tree
+	var parents []plumbing.Hash
+	switch {
+	case errors.Is(err, transport.ErrEmptyRemoteRepository) && branch == g.env.Branch:
+		// The first commit of an empty repository is born on the default branch
+		if repo, err = gogit.Init(memory.NewStorage(), nil); err != nil {
+			return fmt.Errorf("failed to init repository: %w", err)
+		}
+		if _, err = repo.CreateRemote(&config.RemoteConfig{Name: gogit.DefaultRemoteName, URLs: []string{remote}}); err != nil {
+			return fmt.Errorf("failed to add remote: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to fetch branch '%s': %w", branch, pushError(err))
+	default:
+		ref, err := repo.Reference(refName, true)
+		if err != nil {
+			return fmt.Errorf("failed to resolve branch '%s': %w", branch, localError(err))
+		}
+		tip, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return fmt.Errorf("failed to read commit %s: %w", ref.Hash(), err)
+		}
+		if root, err = tip.Tree(); err != nil {
+			return fmt.Errorf("failed to read tree: %w", err)
+		}
+		parents = append(parents, tip.Hash)
+	}
+
+	changes, err := edit(repo.Storer, root)
+	if err != nil {
+		return err
+	}
+	if err := g.env.Limits.checkChanges(repo.Storer, changes); err != nil {
+		return err
+	}
+	committer := object.Signature{Name: g.identity.Name, Email: g.identity.Email, When: time.Now()}
+	hash, err := writeCommit(repo.Storer, root, parents, changes,
+		object.Signature{Name: author.Name, Email: author.Email, When: committer.When}, committer, o.message(message), g.signer)
+	if err != nil {
+		return err
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, hash)); err != nil {
+		return fmt.Errorf("failed to update branch '%s': %w", branch, err)
+	}
+
+	err = repo.PushContext(ctx, &gogit.PushOptions{
+		RefSpecs:        []config.RefSpec{config.RefSpec(refName + ":" + refName)},
+		Auth:            auth,
+		InsecureSkipTLS: g.env.InsecureSkipVerify,
+		CABundle:        caBundle,
+		ProxyOptions:    proxy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to push branch '%s': %w", branch, pushError(err))
+	}
+	o.committed(hash.String())
+	return nil
+}
+
+// pushError maps git transport errors onto the sentinel errors
+func pushError(err error) error {
+	switch {
+	case errors.Is(err, transport.ErrRepositoryNotFound):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	case strings.Contains(err.Error(), "non-fast-forward"), strings.Contains(err.Error(), "fetch first"):
+		// Someone else moved the branch since it was fetched
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	case strings.Contains(err.Error(), "couldn't find remote ref"):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// CreateTag tags target (branch, tag or commit SHA). An empty target uses the configured branch;
+// a non-empty message creates an annotated tag.
+func (g *GiteaAdapter) CreateTag(ctx context.Context, projectID uuid.UUID, name, target, message string, opts ...Option) (*Tag, error) {
+	if target == "" {
+		target = g.env.Branch
+	}
+	g.logger.Info("CreateTag", "projectID", projectID, "tag", name, "target", target)
+	o := g.callOptions(opts)
+
+	tag, resp, err := g.sdk(ctx).CreateTag(o.owner, g.repoName(projectID), gitea.CreateTagOption{
+		TagName: name,
+		Message: message,
+		Target:  target,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag '%s': %w", name, giteaError(resp, err))
+	}
+	return toTag(tag), nil
+}
+
+// ListTags retrieves all tags, following pagination
+// unless WithPage selects a single page
+func (g *GiteaAdapter) ListTags(ctx context.Context, projectID uuid.UUID, opts ...Option) ([]Tag, error) {
+	g.logger.Info("ListTags", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	var tags []Tag
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListRepoTags(o.owner, g.repoName(projectID), gitea.ListRepoTagsOptions{
+			ListOptions: page,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to list tags: %w", giteaError(resp, err))
+		}
+		for _, t := range batch {
+			tags = append(tags, *toTag(t))
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// DeleteTag removes a tag
+func (g *GiteaAdapter) DeleteTag(ctx context.Context, projectID uuid.UUID, name string, opts ...Option) error {
+	g.logger.Info("DeleteTag", "projectID", projectID, "tag", name)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).DeleteTag(o.owner, g.repoName(projectID), name); err != nil {
+		return fmt.Errorf("failed to delete tag '%s': %w", name, giteaError(resp, err))
+	}
+	return nil
+}
+
+// CreateRelease publishes a release, creating its tag from opts.Target when it does not exist yet
+func (g *GiteaAdapter) CreateRelease(ctx context.Context, projectID uuid.UUID, opts ReleaseOptions, options ...Option) (*Release, error) {
+	if opts.Target == "" {
+		opts.Target = g.env.Branch
+	}
+	if opts.Title == "" {
+		opts.Title = opts.TagName
+	}
+	g.logger.Info("CreateRelease", "projectID", projectID, "tag", opts.TagName, "target", opts.Target)
+	o := g.callOptions(options)
+
+	release, resp, err := g.sdk(ctx).CreateRelease(o.owner, g.repoName(projectID), gitea.CreateReleaseOption{
+		TagName:      opts.TagName,
+		Target:       opts.Target,
+		Title:        opts.Title,
+		Note:         opts.Notes,
+		IsDraft:      opts.Draft,
+		IsPrerelease: opts.Prerelease,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release '%s': %w", opts.TagName, giteaError(resp, err))
+	}
+	return toRelease(release), nil
+}
+
+// UploadReleaseAsset attaches a build artifact to a release
+func (g *GiteaAdapter) UploadReleaseAsset(ctx context.Context, projectID uuid.UUID, releaseID int64, name string, r io.Reader, opts ...Option) (*ReleaseAsset, error) {
+	g.logger.Info("UploadReleaseAsset", "projectID", projectID, "release", releaseID, "name", name)
+	o := g.callOptions(opts)
+
+	attachment, resp, err := g.sdk(ctx).CreateReleaseAttachment(o.owner, g.repoName(projectID), releaseID, r, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload release asset '%s': %w", name, giteaError(resp, err))
+	}
+	return toReleaseAsset(attachment), nil
+}
+
+func toTag(t *gitea.Tag) *Tag {
+	tag := &Tag{Name: t.Name, Message: t.Message}
+	if t.Commit != nil {
+		tag.CommitSHA = t.Commit.SHA
+	}
+	return tag
+}
+
+func toRelease(r *gitea.Release) *Release {
+	release := &Release{
+		ID:         r.ID,
+		TagName:    r.TagName,
+		Title:      r.Title,
+		Notes:      r.Note,
+		Draft:      r.IsDraft,
+		Prerelease: r.IsPrerelease,
+		HTMLURL:    r.HTMLURL,
+	}
+	for _, a := range r.Attachments {
+		release.Assets = append(release.Assets, *toReleaseAsset(a))
+	}
+	return release
+}
+
+func toReleaseAsset(a *gitea.Attachment) *ReleaseAsset {
+	return &ReleaseAsset{
+		ID:          a.ID,
+		Name:        a.Name,
+		Size:        a.Size,
+		DownloadURL: a.DownloadURL,
+	}
+}
@@ -0,0 +1,426 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// CreateRepositoryFromTemplate generates the project repository from a Gitea template repository,
+// copying its default branch content, topics and labels, and returns its full name (owner/name).
+// WithOwner and WithIdempotent behave as for CreateRepository, as do the description and visibility
+// of WithRepositoryOptions.
+func (g *GiteaAdapter) CreateRepositoryFromTemplate(ctx context.Context, projectID uuid.UUID, templateOwner, templateRepo string, opts ...Option) (string, error) {
+	g.logger.Info("CreateRepositoryFromTemplate", "projectID", projectID, "template", templateOwner+"/"+templateRepo)
+	o := g.callOptions(opts)
+
+	if o.idempotent {
+		if repo, resp, err := g.sdk(ctx).GetRepo(o.owner, g.repoName(projectID)); err == nil {
+			g.logger.Info("Repository already exists", "projectID", projectID)
+			return repo.FullName, nil
+		} else if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return "", fmt.Errorf("failed to check gitea repository: %w", giteaError(resp, err))
+		}
+	}
+
+	repo, resp, err := g.sdk(ctx).CreateRepoFromTemplate(templateOwner, templateRepo, gitea.CreateRepoFromTemplateOption{
+		Owner:       o.owner,
+		Name:        g.repoName(projectID),
+		Description: cmp.Or(o.repo.Description, "Managed by GitAPI"),
+		Private:     boolOr(o.repo.Private, g.env.CreateRepoPrivate),
+		GitContent:  true,
+		Topics:      true,
+		Labels:      true,
+	})
+	if err != nil {
+		// Lost a race with a concurrent create
+		if o.idempotent && resp != nil && resp.StatusCode == http.StatusConflict {
+			return o.owner + "/" + g.repoName(projectID), nil
+		}
+		return "", fmt.Errorf("failed to create repository from template '%s/%s': %w", templateOwner, templateRepo, giteaError(resp, err))
+	}
+	return repo.FullName, nil
+}
+
+// ForkRepository forks the project repository of the owner WithOwner selects into targetOwner, an
+// organization or the token's own account, keeping the project ID as its name, and returns the fork's
+// full name (owner/name). Changes made in the fork can be proposed back with CreatePullRequest using
+// "targetOwner:branch" as head.
+func (g *GiteaAdapter) ForkRepository(ctx context.Context, projectID uuid.UUID, targetOwner string, opts ...Option) (string, error) {
+	g.logger.Info("ForkRepository", "projectID", projectID, "targetOwner", targetOwner)
+	o := g.callOptions(opts)
+	client := g.sdk(ctx)
+
+	user, resp, err := client.GetMyUserInfo()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", giteaError(resp, err))
+	}
+	opt := gitea.CreateForkOption{}
+	// Gitea forks into the token's own account when no organization is set
+	if !strings.EqualFold(targetOwner, user.UserName) {
+		opt.Organization = &targetOwner
+	}
+	repo, resp, err := client.CreateFork(o.owner, g.repoName(projectID), opt)
+	if err != nil {
+		return "", fmt.Errorf("failed to fork repository into '%s': %w", targetOwner, giteaError(resp, err))
+	}
+	return repo.FullName, nil
+}
+
+// DeleteRepository permanently removes the project repository
+func (g *GiteaAdapter) DeleteRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) error {
+	g.logger.Info("DeleteRepository", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).DeleteRepo(o.owner, g.repoName(projectID)); err != nil {
+		return fmt.Errorf("failed to delete gitea repository: %w", giteaError(resp, err))
+	}
+	return nil
+}
+
+// ArchiveRepository makes the project repository read-only while keeping its history
+func (g *GiteaAdapter) ArchiveRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) error {
+	g.logger.Info("ArchiveRepository", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	archived := true
+	if _, resp, err := g.sdk(ctx).EditRepo(o.owner, g.repoName(projectID), gitea.EditRepoOption{
+		Archived: &archived,
+	}); err != nil {
+		return fmt.Errorf("failed to archive gitea repository: %w", giteaError(resp, err))
+	}
+	return nil
+}
+
+// TransferRepository moves the project repository to another user or organization.
+// Transfers to a user stay pending until that user accepts them.
+func (g *GiteaAdapter) TransferRepository(ctx context.Context, projectID uuid.UUID, newOwner string, opts ...Option) error {
+	g.logger.Info("TransferRepository", "projectID", projectID, "newOwner", newOwner)
+	o := g.callOptions(opts)
+
+	_, resp, err := g.sdk(ctx).TransferRepo(o.owner, g.repoName(projectID), gitea.TransferRepoOption{
+		NewOwner: newOwner,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to transfer repository to '%s': %s: %w", newOwner, repoStatusReason(resp), giteaError(resp, err))
+	}
+	if resp != nil && resp.StatusCode == http.StatusAccepted {
+		g.logger.Warn("Transfer is pending acceptance", "projectID", projectID, "newOwner", newOwner)
+	}
+	return nil
+}
+
+// RenameRepository renames the project repository. Adapter calls address repositories by the
+// name the RepoNamer gives, so a renamed repository is only reachable through the Gitea API afterwards.
+func (g *GiteaAdapter) RenameRepository(ctx context.Context, projectID uuid.UUID, newName string, opts ...Option) error {
+	g.logger.Info("RenameRepository", "projectID", projectID, "newName", newName)
+	o := g.callOptions(opts)
+
+	_, resp, err := g.sdk(ctx).EditRepo(o.owner, g.repoName(projectID), gitea.EditRepoOption{
+		Name: &newName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename repository to '%s': %s: %w", newName, repoStatusReason(resp), giteaError(resp, err))
+	}
+	return nil
+}
+
+// GetTopics returns the topics of the project repository, in the order Gitea stores them
+func (g *GiteaAdapter) GetTopics(ctx context.Context, projectID uuid.UUID, opts ...Option) ([]string, error) {
+	g.logger.Info("GetTopics", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	var topics []string
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListRepoTopics(o.owner, g.repoName(projectID), gitea.ListRepoTopicsOptions{
+			ListOptions: page,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to list topics: %w", giteaError(resp, err))
+		}
+		topics = append(topics, batch...)
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return topics, nil
+}
+
+// SetTopics replaces the topics of the project repository, e.g. "env-prod", "customer-acme" and
+// "template-v3", so SearchRepositories with SearchOptions.Topic finds it. Gitea lowercases topics and
+// rejects ones that are not letters, digits, dashes and dots of up to 35 characters. No topics clears them.
+func (g *GiteaAdapter) SetTopics(ctx context.Context, projectID uuid.UUID, topics []string, opts ...Option) error {
+	g.logger.Info("SetTopics", "projectID", projectID, "topics", topics)
+	o := g.callOptions(opts)
+
+	// Send [] rather than null to clear the topics
+	if resp, err := g.sdk(ctx).SetRepoTopics(o.owner, g.repoName(projectID), append([]string{}, topics...)); err != nil {
+		return fmt.Errorf("failed to set topics: %w", giteaError(resp, err))
+	}
+	return nil
+}
+
+// SetDescription replaces the description shown for the project repository in Gitea
+func (g *GiteaAdapter) SetDescription(ctx context.Context, projectID uuid.UUID, description string, opts ...Option) error {
+	g.logger.Info("SetDescription", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	if _, resp, err := g.sdk(ctx).EditRepo(o.owner, g.repoName(projectID), gitea.EditRepoOption{
+		Description: &description,
+	}); err != nil {
+		return fmt.Errorf("failed to set description: %w", giteaError(resp, err))
+	}
+	return nil
+}
+
+// SetWebsite links the project repository to website, e.g. the deployed project; empty removes the link
+func (g *GiteaAdapter) SetWebsite(ctx context.Context, projectID uuid.UUID, website string, opts ...Option) error {
+	g.logger.Info("SetWebsite", "projectID", projectID, "website", website)
+	o := g.callOptions(opts)
+
+	if _, resp, err := g.sdk(ctx).EditRepo(o.owner, g.repoName(projectID), gitea.EditRepoOption{
+		Website: &website,
+	}); err != nil {
+		return fmt.Errorf("failed to set website: %w", giteaError(resp, err))
+	}
+	return nil
+}
+
+// UpdateRepoSettings applies settings to the project repository, e.g. to bring new repositories
+// in line with an organization policy right after CreateRepository
+func (g *GiteaAdapter) UpdateRepoSettings(ctx context.Context, projectID uuid.UUID, settings RepoSettings, opts ...Option) error {
+	g.logger.Info("UpdateRepoSettings", "projectID", projectID, "mergeStyle", settings.DefaultMergeStyle)
+	o := g.callOptions(opts)
+
+	opt := gitea.EditRepoOption{
+		AllowMerge:                    settings.AllowMerge,
+		AllowRebase:                   settings.AllowRebase,
+		AllowRebaseMerge:              settings.AllowRebaseMerge,
+		AllowSquash:                   settings.AllowSquash,
+		DefaultDeleteBranchAfterMerge: settings.DeleteBranchAfterMerge,
+		HasIssues:                     settings.HasIssues,
+		HasWiki:                       settings.HasWiki,
+		HasProjects:                   settings.HasProjects,
+		HasPullRequests:               settings.HasPullRequests,
+	}
+	if settings.DefaultMergeStyle != "" {
+		style := gitea.MergeStyle(settings.DefaultMergeStyle)
+		opt.DefaultMergeStyle = &style
+	}
+	if _, resp, err := g.sdk(ctx).EditRepo(o.owner, g.repoName(projectID), opt); err != nil {
+		return fmt.Errorf("failed to update repository settings: %w", giteaError(resp, err))
+	}
+	return nil
+}
+
+// GetRepoStats returns the size, commit and branch counts, last activity and language breakdown of
+// the project repository. Empty repositories report zero commits and branches.
+func (g *GiteaAdapter) GetRepoStats(ctx context.Context, projectID uuid.UUID, opts ...Option) (*RepoStats, error) {
+	g.logger.Info("GetRepoStats", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	repo, resp, err := g.sdk(ctx).GetRepo(o.owner, g.repoName(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gitea repository: %w", giteaError(resp, err))
+	}
+	stats := &RepoStats{
+		ProjectID:    projectID,
+		Size:         int64(repo.Size) * 1024,
+		LastActivity: repo.Updated,
+		Languages:    map[string]int64{},
+	}
+	if repo.Empty {
+		return stats, nil
+	}
+
+	base := fmt.Sprintf("/repos/%s/%s", url.PathEscape(o.owner), url.PathEscape(g.repoName(projectID)))
+	stats.Commits, err = g.totalCount(ctx, base+"/commits", url.Values{
+		"sha":          {repo.DefaultBranch},
+		"stat":         {"false"},
+		"verification": {"false"},
+		"files":        {"false"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count commits: %w", err)
+	}
+	stats.Branches, err = g.totalCount(ctx, base+"/branches", url.Values{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count branches: %w", err)
+	}
+
+	languages, resp, err := g.sdk(ctx).GetRepoLanguages(o.owner, g.repoName(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get languages: %w", giteaError(resp, err))
+	}
+	maps.Copy(stats.Languages, languages)
+	return stats, nil
+}
+
+// checkRepoSize enforces Limits.MaxRepoSize on the project repository growing by adding bytes
+func (g *GiteaAdapter) checkRepoSize(ctx context.Context, o callOptions, projectID uuid.UUID, adding int64) error {
+	if g.env.Limits.MaxRepoSize <= 0 {
+		return nil
+	}
+	repo, resp, err := g.sdk(ctx).GetRepo(o.owner, g.repoName(projectID))
+	if err != nil {
+		return fmt.Errorf("failed to check repository size: %w", giteaError(resp, err))
+	}
+	return g.env.Limits.checkRepo(int64(repo.Size)*1024 + adding)
+}
+
+// totalCount reads the X-Total-Count Gitea sets on a listing, fetching a single item of it
+func (g *GiteaAdapter) totalCount(ctx context.Context, path string, query url.Values) (int64, error) {
+	query.Set("limit", "1")
+	resp, err := g.do(ctx, http.MethodGet, path+"?"+query.Encode(), nil, http.Header{"Accept": {"application/json"}})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := responseError(resp); err != nil {
+		return 0, err
+	}
+	total, err := strconv.ParseInt(resp.Header.Get("X-Total-Count"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("missing total count: %w", err)
+	}
+	return total, nil
+}
+
+// repoStatusReason explains the status codes Gitea returns for repository moves
+func repoStatusReason(resp *gitea.Response) string {
+	if resp == nil {
+		return "no response"
+	}
+	switch resp.StatusCode {
+	case http.StatusForbidden:
+		return "not permitted"
+	case http.StatusNotFound:
+		return "repository or owner not found"
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		return "target name already taken"
+	default:
+		return resp.Status
+	}
+}
+
+// ListRepositories returns every repository of the configured owner, or of WithOwner, e.g. to
+// reconcile the project database with Gitea. WithPage selects a single page.
+func (g *GiteaAdapter) ListRepositories(ctx context.Context, opts ...Option) ([]Repository, error) {
+	o := g.callOptions(opts)
+	return g.SearchRepositories(ctx, "", SearchOptions{Owner: o.owner, Page: o.page, Limit: o.limit})
+}
+
+// SearchRepositories returns the repositories of opts.Owner whose name contains query,
+// sorted by name. An empty query matches every repository.
+func (g *GiteaAdapter) SearchRepositories(ctx context.Context, query string, opts SearchOptions) ([]Repository, error) {
+	owner := cmp.Or(opts.Owner, g.env.Owner)
+	g.logger.Info("SearchRepositories", "owner", owner, "query", query)
+
+	user, resp, err := g.sdk(ctx).GetUserInfo(owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up owner '%s': %w", owner, giteaError(resp, err))
+	}
+	search := gitea.SearchRepoOptions{
+		Keyword:        query,
+		KeywordIsTopic: opts.Topic,
+		OwnerID:        user.ID,
+		Sort:           "alpha",
+		Order:          "asc",
+	}
+	if opts.ExcludeArchived {
+		search.IsArchived = gitea.OptionalBool(false)
+	}
+
+	var repos []Repository
+	pages := callOptions{page: opts.Page, limit: cmp.Or(opts.Limit, defaultPageSize)}
+	err = listPages(pages, func(page gitea.ListOptions) (*gitea.Response, error) {
+		search.ListOptions = page
+		batch, resp, err := g.sdk(ctx).SearchRepos(search)
+		if err != nil {
+			return resp, fmt.Errorf("failed to search repositories: %w", giteaError(resp, err))
+		}
+		for _, r := range batch {
+			repos = append(repos, toRepository(r))
+			if opts.Limit > 0 && len(repos) == opts.Limit {
+				return nil, nil // A nil response ends the listing
+			}
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+func toRepository(r *gitea.Repository) Repository {
+	repo := Repository{
+		Name:          r.Name,
+		FullName:      r.FullName,
+		Description:   r.Description,
+		Website:       r.Website,
+		DefaultBranch: r.DefaultBranch,
+		Private:       r.Private,
+		Archived:      r.Archived,
+		Empty:         r.Empty,
+		HTMLURL:       r.HTMLURL,
+		CloneURL:      r.CloneURL,
+		CreatedAt:     r.Created,
+		UpdatedAt:     r.Updated,
+	}
+	if r.Owner != nil {
+		repo.Owner = r.Owner.UserName
+	}
+	if id, err := ProjectIDFromRepoName(r.Name); err == nil {
+		repo.ProjectID = id
+	}
+	return repo
+}
+
+// AddSubmodule pins the repository at url to commit sha under path, registering it in .gitmodules.
+// The contents API cannot write gitlinks, so the commit is pushed over git.
+func (g *GiteaAdapter) AddSubmodule(ctx context.Context, projectID uuid.UUID, path, url, sha, message string, opts ...Option) error {
+	g.logger.Info("AddSubmodule", "projectID", projectID, "path", path, "url", url, "sha", sha)
+	o := g.callOptions(opts)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	return g.pushChanges(ctx, projectID, o, message, addSubmodule(path, url, sha))
+}
+
+// UpdateSubmodule points the existing submodule at path to commit sha
+func (g *GiteaAdapter) UpdateSubmodule(ctx context.Context, projectID uuid.UUID, path, sha, message string, opts ...Option) error {
+	g.logger.Info("UpdateSubmodule", "projectID", projectID, "path", path, "sha", sha)
+	o := g.callOptions(opts)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	return g.pushChanges(ctx, projectID, o, message, updateSubmodule(path, sha))
+}
+
+// CreateSymlink adds a symbolic link at path pointing to target, which is stored as given.
+// Like AddSubmodule, the commit is pushed over git since the contents API only writes files.
+func (g *GiteaAdapter) CreateSymlink(ctx context.Context, projectID uuid.UUID, path, target, message string, opts ...Option) error {
+	g.logger.Info("CreateSymlink", "projectID", projectID, "path", path, "target", target)
+	o := g.callOptions(opts)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	return g.pushChanges(ctx, projectID, o, message, createSymlink(path, target))
+}
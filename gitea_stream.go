@@ -0,0 +1,193 @@
+package git
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/google/uuid"
+)
+
+// OpenFile streams a file at ref (branch, tag or SHA; empty for the default branch) from the raw
+// media endpoint, which also resolves LFS pointers. The caller must close the reader.
+func (g *GiteaAdapter) OpenFile(ctx context.Context, projectID uuid.UUID, filePath, ref string, opts ...Option) (io.ReadCloser, error) {
+	if err := validatePath(filePath); err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		ref = g.env.Branch
+	}
+	g.logger.Info("OpenFile", "projectID", projectID, "path", filePath, "ref", ref)
+	o := g.callOptions(opts)
+
+	resp, err := g.do(ctx, http.MethodGet,
+		fmt.Sprintf("/repos/%s/%s/media/%s?ref=%s", o.owner, g.repoName(projectID), escapePath(filePath), url.QueryEscape(ref)),
+		nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	if err := responseError(resp); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// rawFile reads filePath from the raw endpoint, which sends the bytes as they are instead of base64
+// inside JSON. It returns nil, leaving the contents API to answer, for files under minSize bytes,
+// which may be symlinks the raw endpoint cannot tell apart, and for paths without raw content.
+func (g *GiteaAdapter) rawFile(ctx context.Context, o callOptions, projectID uuid.UUID, filePath string, minSize int64) (*FileNode, error) {
+	resp, err := g.do(ctx, http.MethodGet,
+		fmt.Sprintf("/repos/%s/%s/raw/%s?ref=%s", o.owner, g.repoName(projectID), escapePath(filePath), url.QueryEscape(o.branch)),
+		nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw file: %w", err)
+	}
+	defer resp.Body.Close()
+	// Directories and submodules have no raw content; the contents API also reports missing files
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := responseError(resp); err != nil {
+		return nil, fmt.Errorf("failed to get raw file: %w", err)
+	}
+	// An unknown length, e.g. of a compressed response, may be a small file
+	if minSize > 0 && resp.ContentLength < minSize {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read raw file: %w", err)
+	}
+	filePath = strings.Trim(filePath, "/")
+	node := &FileNode{
+		Name: path.Base(filePath),
+		Path: filePath,
+		Type: FileTypeFile,
+		// Gitea tags raw blobs with their SHA
+		SHA: strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	if node.SHA == "" {
+		node.SHA = plumbing.ComputeHash(plumbing.BlobObject, data).String()
+	}
+	node.setContent(data)
+	return node, nil
+}
+
+// WriteFile creates or updates a file from r, base64-encoding it on the fly so the
+// content is never held in memory as a whole.
+func (g *GiteaAdapter) WriteFile(ctx context.Context, projectID uuid.UUID, filePath string, r io.Reader, message string, opts ...Option) error {
+	g.logger.Info("WriteFile", "projectID", projectID, "path", filePath, "message", message)
+	o := g.callOptions(opts)
+
+	if err := validatePath(filePath); err != nil {
+		return err
+	}
+
+	// The parent listing carries the SHA without downloading the current content
+	sha, err := g.entrySHA(ctx, o.owner, projectID, o.branch, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to check existing file: %w", err)
+	}
+	if err := checkExpectedSHA(filePath, o.expectedSHA, sha); err != nil {
+		return err
+	}
+	if err := g.checkRepoSize(ctx, o, projectID, 0); err != nil {
+		return err
+	}
+	limited := g.env.Limits.reader(filePath, r)
+	r = limited
+	if g.signer != nil {
+		return g.pushChanges(ctx, projectID, o, message, func(s storer.EncodedObjectStorer, root *object.Tree) (map[string]*localChange, error) {
+			hash, err := storeBlob(s, r)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]*localChange{filePath: {Hash: hash}}, nil
+		})
+	}
+
+	author, err := g.author(ctx, o)
+	if err != nil {
+		return err
+	}
+	options := struct {
+		gitea.FileOptions
+		SHA string `json:"sha,omitempty"`
+	}{
+		FileOptions: gitea.FileOptions{
+			Message:    o.message(message),
+			BranchName: o.branch,
+			Author:     author,
+			Committer:  *g.identity,
+		},
+		SHA: sha,
+	}
+	head, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to encode request body: %w", err)
+	}
+	// Reopen the object to append the streamed content field
+	head = append(head[:len(head)-1], `,"content":"`...)
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go func() {
+		_, err := pw.Write(head)
+		if err == nil {
+			enc := base64.NewEncoder(base64.StdEncoding, pw)
+			if _, err = io.Copy(enc, r); err == nil {
+				err = enc.Close()
+			}
+		}
+		if err == nil {
+			_, err = io.WriteString(pw, `"}`)
+		}
+		pw.CloseWithError(err)
+	}()
+
+	method := http.MethodPost
+	if sha != "" {
+		method = http.MethodPut
+	}
+	resp, err := g.do(ctx, method,
+		fmt.Sprintf("/repos/%s/%s/contents/%s", o.owner, g.repoName(projectID), escapePath(filePath)),
+		pr, http.Header{"Content-Type": {"application/json"}, "Accept": {"application/json"}})
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := responseError(resp); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	var file gitea.FileResponse
+	if json.NewDecoder(resp.Body).Decode(&file) == nil {
+		g.committed(o, &file)
+	}
+	return nil
+}
+
+// entrySHA returns the blob SHA of filePath at ref, or "" when it does not exist
+func (g *GiteaAdapter) entrySHA(ctx context.Context, owner string, projectID uuid.UUID, ref, filePath string) (string, error) {
+	node, err := g.stat(ctx, owner, projectID, ref, filePath)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return node.SHA, nil
+}
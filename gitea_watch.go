@@ -0,0 +1,137 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// watchers wakes the WatchRepository loops of a project ahead of their next poll
+type watchers struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[chan struct{}]struct{}
+}
+
+func (w *watchers) add(projectID uuid.UUID) chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.subs == nil {
+		w.subs = map[uuid.UUID]map[chan struct{}]struct{}{}
+	}
+	if w.subs[projectID] == nil {
+		w.subs[projectID] = map[chan struct{}]struct{}{}
+	}
+	wake := make(chan struct{}, 1)
+	w.subs[projectID][wake] = struct{}{}
+	return wake
+}
+
+func (w *watchers) remove(projectID uuid.UUID, wake chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subs[projectID], wake)
+	if len(w.subs[projectID]) == 0 {
+		delete(w.subs, projectID)
+	}
+}
+
+func (w *watchers) notify(projectID uuid.UUID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for wake := range w.subs[projectID] {
+		// A pending wake-up already covers this one
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// WatchRepository polls the tip of the branch (WithBranch, default the configured branch) every
+// interval and sends a ChangeEvent whenever it moves, e.g. after a human edit in the Gitea UI.
+// Changes between two polls are coalesced into one event. Call NotifyChange from a webhook
+// handler to poll right away, so interval only bounds the delay when a delivery is lost.
+// Failed polls are logged and retried on the next tick. The channel is closed when ctx is done.
+func (g *GiteaAdapter) WatchRepository(ctx context.Context, projectID uuid.UUID, interval time.Duration, opts ...Option) (<-chan ChangeEvent, error) {
+	g.logger.Info("WatchRepository", "projectID", projectID, "interval", interval)
+	o := g.callOptions(opts)
+	if interval <= 0 {
+		return nil, fmt.Errorf("failed to watch repository: interval must be positive, got %s", interval)
+	}
+
+	last, err := g.branchTip(ctx, o, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch repository: %w", err)
+	}
+
+	events := make(chan ChangeEvent)
+	wake := g.watchers.add(projectID)
+	go func() {
+		defer close(events)
+		defer g.watchers.remove(projectID, wake)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-wake:
+			}
+
+			tip, err := g.branchTip(ctx, o, projectID)
+			if err != nil {
+				if ctx.Err() == nil {
+					g.logger.Warn("Failed to poll repository", "projectID", projectID, "branch", o.branch, "error", err)
+				}
+				continue
+			}
+			if tip == last {
+				continue
+			}
+			event := ChangeEvent{
+				ProjectID:  projectID,
+				Branch:     o.branch,
+				BeforeSHA:  last,
+				AfterSHA:   tip,
+				DetectedAt: time.Now(),
+			}
+			select {
+			case events <- event:
+				last = tip
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// NotifyChange makes every WatchRepository loop of projectID poll now, e.g. on a push webhook:
+//
+//	event, err := webhook.ParsePushEvent(r, secret)
+//	if err == nil {
+//		if id, err := event.ProjectID(); err == nil {
+//			adapter.NotifyChange(id)
+//		}
+//	}
+func (g *GiteaAdapter) NotifyChange(projectID uuid.UUID) {
+	g.watchers.notify(projectID)
+}
+
+// branchTip returns the commit SHA the branch points at, or "" once the branch is gone
+func (g *GiteaAdapter) branchTip(ctx context.Context, o callOptions, projectID uuid.UUID) (string, error) {
+	branch, resp, err := g.sdk(ctx).GetRepoBranch(o.owner, g.repoName(projectID), o.branch)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch '%s': %w", o.branch, giteaError(resp, err))
+	}
+	return toBranch(branch).CommitSHA, nil
+}
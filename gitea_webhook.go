@@ -0,0 +1,83 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/google/uuid"
+)
+
+// CreateWebhook registers a Gitea webhook that posts JSON payloads to opts.URL.
+// Events defaults to push and pull_request; the hook is created active.
+func (g *GiteaAdapter) CreateWebhook(ctx context.Context, projectID uuid.UUID, opts WebhookOptions, options ...Option) (*Webhook, error) {
+	if len(opts.Events) == 0 {
+		opts.Events = []string{WebhookEventPush, WebhookEventPullRequest}
+	}
+	g.logger.Info("CreateWebhook", "projectID", projectID, "url", opts.URL, "events", opts.Events)
+	o := g.callOptions(options)
+
+	config := map[string]string{"url": opts.URL, "content_type": "json"}
+	if opts.Secret != "" {
+		config["secret"] = opts.Secret
+	}
+	hook, resp, err := g.sdk(ctx).CreateRepoHook(o.owner, g.repoName(projectID), gitea.CreateHookOption{
+		Type:         gitea.HookTypeGitea,
+		Config:       config,
+		Events:       opts.Events,
+		BranchFilter: opts.BranchFilter,
+		Active:       true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", giteaError(resp, err))
+	}
+	return toWebhook(hook), nil
+}
+
+// ListWebhooks retrieves all webhooks of the repository, following pagination
+// unless WithPage selects a single page
+func (g *GiteaAdapter) ListWebhooks(ctx context.Context, projectID uuid.UUID, opts ...Option) ([]Webhook, error) {
+	g.logger.Info("ListWebhooks", "projectID", projectID)
+	o := g.callOptions(opts)
+
+	var hooks []Webhook
+	err := listPages(o, func(page gitea.ListOptions) (*gitea.Response, error) {
+		batch, resp, err := g.sdk(ctx).ListRepoHooks(o.owner, g.repoName(projectID), gitea.ListHooksOptions{
+			ListOptions: page,
+		})
+		if err != nil {
+			return resp, fmt.Errorf("failed to list webhooks: %w", giteaError(resp, err))
+		}
+		for _, h := range batch {
+			hooks = append(hooks, *toWebhook(h))
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook removes a webhook by ID
+func (g *GiteaAdapter) DeleteWebhook(ctx context.Context, projectID uuid.UUID, id int64, opts ...Option) error {
+	g.logger.Info("DeleteWebhook", "projectID", projectID, "id", id)
+	o := g.callOptions(opts)
+
+	if resp, err := g.sdk(ctx).DeleteRepoHook(o.owner, g.repoName(projectID), id); err != nil {
+		return fmt.Errorf("failed to delete webhook %d: %w", id, giteaError(resp, err))
+	}
+	return nil
+}
+
+// toWebhook converts a Gitea hook; the secret is write-only and never returned by the server
+func toWebhook(h *gitea.Hook) *Webhook {
+	return &Webhook{
+		ID:           h.ID,
+		URL:          h.Config["url"],
+		Events:       h.Events,
+		BranchFilter: h.BranchFilter,
+		Active:       h.Active,
+		CreatedAt:    h.Created,
+	}
+}
@@ -0,0 +1,19 @@
+package git
+
+import (
+	"slices"
+	"time"
+)
+
+// gcCronTask is the Gitea cron task that runs git gc over every repository
+const gcCronTask = "git_gc_repos"
+
+// expendable reports whether branch may be deleted under p at all, before checking it is stale or merged
+func (p HousekeepingPolicy) expendable(branch Branch, defaultBranch string) bool {
+	return branch.Name != defaultBranch && !branch.Protected && !slices.Contains(p.Keep, branch.Name)
+}
+
+// stale reports whether the tip of branch is older than p.StaleAfter
+func (p HousekeepingPolicy) stale(branch Branch, now time.Time) bool {
+	return p.StaleAfter > 0 && !branch.UpdatedAt.IsZero() && now.Sub(branch.UpdatedAt) > p.StaleAfter
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	lfsSpec = "https://git-lfs.github.com/spec/v1"
+
+	// maxLFSPointerSize is the largest pointer git-lfs will parse
+	maxLFSPointerSize = 1024
+)
+
+// parseLFSPointer returns the pointer data is, or nil when data is regular content
+func parseLFSPointer(data []byte) *LFSPointer {
+	if len(data) > maxLFSPointerSize || !strings.HasPrefix(string(data), "version "+lfsSpec+"\n") {
+		return nil
+	}
+
+	pointer := &LFSPointer{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")[1:] {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "oid":
+			hash, ok := strings.CutPrefix(value, "sha256:")
+			if !ok || len(hash) != 64 {
+				return nil
+			}
+			pointer.OID = hash
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return nil
+			}
+			pointer.Size = size
+		}
+	}
+	if pointer.OID == "" {
+		return nil
+	}
+	return pointer
+}
+
+// String renders the pointer file committed in place of the object
+func (p LFSPointer) String() string {
+	return fmt.Sprintf("version %s\noid sha256:%s\nsize %d\n", lfsSpec, p.OID, p.Size)
+}
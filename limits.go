@@ -0,0 +1,118 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case LimitFileSize:
+		return fmt.Sprintf("file '%s' exceeds the size limit of %d bytes: %s", e.Path, e.Max, ErrLimit)
+	case LimitRepoSize:
+		return fmt.Sprintf("repository would grow to %d bytes, over the limit of %d: %s", e.Value, e.Max, ErrLimit)
+	case LimitScaffoldFiles:
+		return fmt.Sprintf("scaffold of %d files exceeds the limit of %d: %s", e.Value, e.Max, ErrLimit)
+	}
+	return fmt.Sprintf("%s %d exceeds %d: %s", e.Limit, e.Value, e.Max, ErrLimit)
+}
+
+// Unwrap makes a LimitError match ErrLimit
+func (e *LimitError) Unwrap() error {
+	return ErrLimit
+}
+
+// checkFile enforces MaxFileSize on a file of size bytes
+func (l Limits) checkFile(path string, size int64) error {
+	if l.MaxFileSize > 0 && size > l.MaxFileSize {
+		return &LimitError{Limit: LimitFileSize, Path: path, Value: size, Max: l.MaxFileSize}
+	}
+	return nil
+}
+
+// checkRepo enforces MaxRepoSize on a repository of size bytes
+func (l Limits) checkRepo(size int64) error {
+	if l.MaxRepoSize > 0 && size > l.MaxRepoSize {
+		return &LimitError{Limit: LimitRepoSize, Value: size, Max: l.MaxRepoSize}
+	}
+	return nil
+}
+
+// checkScaffold enforces MaxScaffoldFiles on a scaffold of count files
+func (l Limits) checkScaffold(count int) error {
+	if l.MaxScaffoldFiles > 0 && count > l.MaxScaffoldFiles {
+		return &LimitError{Limit: LimitScaffoldFiles, Value: int64(count), Max: int64(l.MaxScaffoldFiles)}
+	}
+	return nil
+}
+
+// checkChanges enforces MaxFileSize on the blobs changes write, already stored in s.
+// Deletions and gitlinks are not counted.
+func (l Limits) checkChanges(s storer.EncodedObjectStorer, changes map[string]*localChange) error {
+	if l.MaxFileSize <= 0 {
+		return nil
+	}
+	for path, change := range changes {
+		if change == nil || change.Mode == filemode.Submodule {
+			continue
+		}
+		obj, err := s.EncodedObject(plumbing.BlobObject, change.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to read blob %s: %w", change.Hash, err)
+		}
+		if err := l.checkFile(path, obj.Size()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reader fails reads of r past MaxFileSize with a LimitError, so oversized streams stop early
+func (l Limits) reader(path string, r io.Reader) *limitReader {
+	return &limitReader{r: r, path: path, limits: l}
+}
+
+// limitReader counts the bytes read through it, see Limits.reader
+type limitReader struct {
+	r      io.Reader
+	path   string
+	limits Limits
+	n      int64
+	err    error // the LimitError once the limit was crossed
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if limitErr := r.limits.checkFile(r.path, r.n); limitErr != nil {
+		r.err = limitErr
+		return n, limitErr
+	}
+	return n, err
+}
+
+// dirSize sums the sizes of the files below root
+func dirSize(root string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,1621 @@
+package git
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/google/uuid"
+	"github.com/kelseyhightower/envconfig"
+)
+
+// localChange is a pending tree entry update. A nil *localChange deletes the path.
+// A zero Mode keeps an existing executable bit and otherwise means filemode.Regular.
+type localChange struct {
+	Hash plumbing.Hash
+	Mode filemode.FileMode
+}
+
+var _ Adapter = (*LocalGitAdapter)(nil)
+
+func NewLocalGitAdapter() (*LocalGitAdapter, error) {
+	// Load configuration from the environment.
+	env := &LocalGitConfig{}
+	if err := envconfig.Process("ORCHESTRATOR", env); err != nil {
+		return nil, err
+	}
+	return NewLocalGitAdapterFromConfig(env)
+}
+
+// NewLocalGitAdapterFromConfig builds the adapter from cfg without reading the environment
+func NewLocalGitAdapterFromConfig(cfg *LocalGitConfig) (*LocalGitAdapter, error) {
+	if cfg == nil || cfg.Root == "" || cfg.Branch == "" {
+		return nil, fmt.Errorf("invalid local git config: Root and Branch are required")
+	}
+	env := *cfg
+
+	if err := os.MkdirAll(env.Root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local repository root: %w", err)
+	}
+
+	logger := env.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	signer, err := loadSigner(env.SigningKey, env.SigningKeyPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalGitAdapter{logger: logger, env: &env, signer: signer}, nil
+}
+
+// GetFile retrieves a file from the branch tip
+func (l *LocalGitAdapter) GetFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	l.logger.Info("GetFile", "projectID", projectID, "path", path)
+	o := newCallOptions(l.env.Branch, opts)
+
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
+	repo, tree, err := l.openTree(projectID, o.branch)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return nil, fmt.Errorf("failed to get file contents: branch '%s' has no commits: %w", o.branch, ErrNotFound)
+	}
+	if entry, err := tree.FindEntry(strings.Trim(path, "/")); err == nil {
+		if err := o.notModified(path, entry.Hash.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.fileNode(repo, tree, path)
+}
+
+// GetFiles reads paths from a single tree. Files that could be read are returned even when
+// others fail; the error joins the per-path failures.
+func (l *LocalGitAdapter) GetFiles(ctx context.Context, projectID uuid.UUID, paths []string, opts ...Option) (map[string]*FileNode, error) {
+	l.logger.Info("GetFiles", "projectID", projectID, "files", len(paths))
+	o := newCallOptions(l.env.Branch, opts)
+
+	if err := validatePaths(paths...); err != nil {
+		return nil, err
+	}
+
+	repo, tree, err := l.openTree(projectID, o.branch)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return nil, fmt.Errorf("failed to get file contents: branch '%s' has no commits: %w", o.branch, ErrNotFound)
+	}
+
+	files := make(map[string]*FileNode, len(paths))
+	var failed []error
+	for _, path := range paths {
+		node, err := l.fileNode(repo, tree, path)
+		if err != nil {
+			failed = append(failed, fmt.Errorf("path %s: %w", path, err))
+			continue
+		}
+		files[path] = node
+	}
+	return files, errors.Join(failed...)
+}
+
+// StatFile returns the type, SHA and size of a file or directory without reading file content
+func (l *LocalGitAdapter) StatFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	l.logger.Info("StatFile", "projectID", projectID, "path", path)
+	o := newCallOptions(l.env.Branch, opts)
+
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
+	repo, tree, err := l.openTree(projectID, o.branch)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return nil, fmt.Errorf("failed to stat '%s': branch '%s' has no commits: %w", path, o.branch, ErrNotFound)
+	}
+
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return &FileNode{Type: FileTypeDir, Mode: FileModeDir, SHA: tree.Hash.String()}, nil
+	}
+	entry, err := tree.FindEntry(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat '%s': %w", path, localError(err))
+	}
+
+	node := &FileNode{Name: entry.Name, Path: path, Mode: fileModeOf(entry.Mode), SHA: entry.Hash.String()}
+	switch entry.Mode {
+	case filemode.Dir:
+		node.Type = FileTypeDir
+	case filemode.Symlink:
+		node.Type = FileTypeSymlink
+		if target, err := l.readBlob(repo, entry.Hash); err == nil {
+			node.Target = &target
+			node.Size = int64(len(target))
+		}
+	case filemode.Submodule:
+		node.Type = FileTypeSubmodule
+		node.SubmoduleURL = treeSubmodules(tree)[path]
+	default:
+		node.Type = FileTypeFile
+		// Reading the object header gives the size without loading the blob
+		if size, err := repo.Storer.EncodedObjectSize(entry.Hash); err == nil {
+			node.Size = size
+		}
+	}
+	return node, nil
+}
+
+// GetFileAtRef retrieves a file as of ref, which may be a branch name, tag or commit SHA
+func (l *LocalGitAdapter) GetFileAtRef(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) (*FileNode, error) {
+	l.logger.Info("GetFileAtRef", "projectID", projectID, "path", path, "ref", ref)
+
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := l.refTree(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.fileNode(repo, tree, path)
+}
+
+// TreeHash returns the git tree SHA of directory path as of ref, "" for the root. An empty ref reads
+// the branch of WithBranch or the configured one. It changes exactly when something under path does.
+func (l *LocalGitAdapter) TreeHash(ctx context.Context, projectID uuid.UUID, ref, path string, opts ...Option) (string, error) {
+	l.logger.Info("TreeHash", "projectID", projectID, "ref", ref, "path", path)
+	o := newCallOptions(l.env.Branch, append(slices.Clone(opts), WithBranch(ref)))
+
+	if err := validatePath(path); err != nil {
+		return "", err
+	}
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return "", err
+	}
+	tree, err := l.refTree(repo, o.branch)
+	if err != nil {
+		return "", err
+	}
+
+	path = strings.Trim(path, "/")
+	if path == "" || path == "." {
+		return tree.Hash.String(), nil
+	}
+	sub, err := tree.Tree(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash tree '%s': %w", path, localError(err))
+	}
+	return sub.Hash.String(), nil
+}
+
+// GetDiff returns the per-file changes needed to turn base into head (branches, tags or SHAs)
+func (l *LocalGitAdapter) GetDiff(ctx context.Context, projectID uuid.UUID, base, head string, opts ...Option) (*Diff, error) {
+	l.logger.Info("GetDiff", "projectID", projectID, "base", base, "head", head)
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	trees := map[string]*object.Tree{}
+	indexes := map[string]map[string]string{}
+	for _, ref := range []string{base, head} {
+		if trees[ref], err = l.refTree(repo, ref); err != nil {
+			return nil, err
+		}
+		index := map[string]string{}
+		err = trees[ref].Files().ForEach(func(f *object.File) error {
+			index[f.Name] = f.Hash.String()
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk tree at '%s': %w", ref, err)
+		}
+		indexes[ref] = index
+	}
+
+	return buildDiff(base, head, indexes[base], indexes[head], func(ref, path string) (string, error) {
+		file, err := trees[ref].File(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read '%s' at '%s': %w", path, ref, err)
+		}
+		return l.readBlob(repo, file.Hash)
+	})
+}
+
+// CompareRefs counts the commits head is ahead of and behind base (branches, tags or SHAs) and lists
+// the files that differ between them
+func (l *LocalGitAdapter) CompareRefs(ctx context.Context, projectID uuid.UUID, base, head string) (*Comparison, error) {
+	l.logger.Info("CompareRefs", "projectID", projectID, "base", base, "head", head)
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := map[string]*object.Commit{}
+	indexes := map[string]map[string]string{}
+	for _, ref := range []string{base, head} {
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ref '%s': %w", ref, localError(err))
+		}
+		if commits[ref], err = repo.CommitObject(*hash); err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+		}
+		tree, err := commits[ref].Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tree: %w", err)
+		}
+		index := map[string]string{}
+		err = tree.Files().ForEach(func(f *object.File) error {
+			index[f.Name] = f.Hash.String()
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk tree at '%s': %w", ref, err)
+		}
+		indexes[ref] = index
+	}
+
+	ahead, err := countExclusive(commits[head], commits[base])
+	if err != nil {
+		return nil, err
+	}
+	behind, err := countExclusive(commits[base], commits[head])
+	if err != nil {
+		return nil, err
+	}
+	return &Comparison{
+		Base:     base,
+		Head:     head,
+		AheadBy:  ahead,
+		BehindBy: behind,
+		Files:    compareIndexes(indexes[base], indexes[head]),
+	}, nil
+}
+
+// countExclusive counts the commits reachable from tip but not from other
+func countExclusive(tip, other *object.Commit) (int, error) {
+	reachable := map[plumbing.Hash]bool{}
+	err := object.NewCommitPreorderIter(other, nil, nil).ForEach(func(c *object.Commit) error {
+		reachable[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to walk history: %w", err)
+	}
+
+	count := 0
+	err = object.NewCommitPreorderIter(tip, reachable, nil).ForEach(func(*object.Commit) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to walk history: %w", err)
+	}
+	return count, nil
+}
+
+// BlameFile attributes every line of path at ref (default branch when empty) to the commit
+// that last changed it
+func (l *LocalGitAdapter) BlameFile(ctx context.Context, projectID uuid.UUID, path, ref string) ([]BlameRange, error) {
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+	ref = cmp.Or(ref, l.env.Branch)
+	l.logger.Info("BlameFile", "projectID", projectID, "path", path, "ref", ref)
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref '%s': %w", ref, localError(err))
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	result, err := gogit.Blame(commit, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame '%s': %w", path, localError(err))
+	}
+
+	lines := make([]Commit, len(result.Lines))
+	for i, line := range result.Lines {
+		lines[i] = Commit{SHA: line.Hash.String(), AuthorName: line.AuthorName, AuthorEmail: line.Author, Timestamp: line.Date}
+	}
+	return blameRanges(lines), nil
+}
+
+// RevertCommit undoes the changes sha made, relative to its first parent, in a new commit on the
+// branch. An empty message defaults to "Revert <sha>". It fails with ErrConflict when a later
+// commit has changed one of the same files.
+func (l *LocalGitAdapter) RevertCommit(ctx context.Context, projectID uuid.UUID, sha, message string, opts ...Option) error {
+	l.logger.Info("RevertCommit", "projectID", projectID, "sha", sha)
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(sha))
+	if err != nil {
+		return fmt.Errorf("failed to resolve ref '%s': %w", sha, localError(err))
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	if commit.NumParents() == 0 {
+		return fmt.Errorf("failed to revert %s: root commit", sha)
+	}
+	return revertCommit(ctx, l, l.logger, projectID, hash.String(), commit.ParentHashes[0].String(), message, opts)
+}
+
+// CherryPick applies the changes sha made, relative to its first parent, to targetBranch in a new
+// commit that keeps the original message and notes where it came from. Files changed on the target
+// since are merged line by line; it fails with ErrConflict, committing nothing, when edits overlap.
+func (l *LocalGitAdapter) CherryPick(ctx context.Context, projectID uuid.UUID, sha, targetBranch string, opts ...Option) error {
+	l.logger.Info("CherryPick", "projectID", projectID, "sha", sha, "targetBranch", targetBranch)
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(sha))
+	if err != nil {
+		return fmt.Errorf("failed to resolve ref '%s': %w", sha, localError(err))
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	if commit.NumParents() == 0 {
+		return fmt.Errorf("failed to cherry-pick %s: root commit", sha)
+	}
+	return cherryPick(ctx, l, l.logger, projectID, hash.String(), commit.ParentHashes[0].String(), commit.Message, append(slices.Clone(opts), WithBranch(targetBranch)))
+}
+
+// RestoreFile commits the content path had at ref (a branch, tag or commit SHA) onto the branch
+func (l *LocalGitAdapter) RestoreFile(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) error {
+	l.logger.Info("RestoreFile", "projectID", projectID, "path", path, "ref", ref)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	return restoreFile(ctx, l, projectID, path, ref, opts)
+}
+
+// AddSubmodule pins the repository at url to commit sha under path, registering it in .gitmodules
+func (l *LocalGitAdapter) AddSubmodule(ctx context.Context, projectID uuid.UUID, path, url, sha, message string, opts ...Option) error {
+	l.logger.Info("AddSubmodule", "projectID", projectID, "path", path, "url", url, "sha", sha)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	return l.editTree(projectID, message, opts, addSubmodule(path, url, sha))
+}
+
+// UpdateSubmodule points the existing submodule at path to commit sha
+func (l *LocalGitAdapter) UpdateSubmodule(ctx context.Context, projectID uuid.UUID, path, sha, message string, opts ...Option) error {
+	l.logger.Info("UpdateSubmodule", "projectID", projectID, "path", path, "sha", sha)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	return l.editTree(projectID, message, opts, updateSubmodule(path, sha))
+}
+
+// CreateSymlink adds a symbolic link at path pointing to target, which is stored as given
+func (l *LocalGitAdapter) CreateSymlink(ctx context.Context, projectID uuid.UUID, path, target, message string, opts ...Option) error {
+	l.logger.Info("CreateSymlink", "projectID", projectID, "path", path, "target", target)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	return l.editTree(projectID, message, opts, createSymlink(path, target))
+}
+
+// ListFiles retrieves files. If path is empty, lists root.
+// If path not set ("", "."), it recursively fetches all files and directories.
+func (l *LocalGitAdapter) ListFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	l.logger.Info("ListFiles", "projectID", projectID, "path", path)
+	o := newCallOptions(l.env.Branch, opts)
+
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
+	isRecursive := false
+	switch path {
+	case ".", "":
+		path = ""
+		isRecursive = true
+	}
+
+	repo, tree, err := l.openTree(projectID, o.branch)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return nil, nil
+	}
+	modules := treeSubmodules(tree)
+	if path != "" {
+		if tree, err = tree.Tree(path); err != nil {
+			return nil, fmt.Errorf("failed to list contents at path '%s': %w", path, localError(err))
+		}
+	}
+	if err := o.notModified(path, tree.Hash.String()); err != nil {
+		return nil, err
+	}
+
+	nodes, err := l.listTree(repo, tree, path, isRecursive, modules)
+	return paginate(o, nodes), err
+}
+
+// ListFilesRecursive lists everything below path, populating Children for directories
+func (l *LocalGitAdapter) ListFilesRecursive(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	l.logger.Info("ListFilesRecursive", "projectID", projectID, "path", path)
+	o := newCallOptions(l.env.Branch, opts)
+
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
+	path = strings.Trim(path, "/")
+	if path == "." {
+		path = ""
+	}
+
+	repo, tree, err := l.openTree(projectID, o.branch)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return nil, nil
+	}
+	modules := treeSubmodules(tree)
+	if path != "" {
+		if tree, err = tree.Tree(path); err != nil {
+			return nil, fmt.Errorf("failed to list contents at path '%s': %w", path, localError(err))
+		}
+	}
+	if err := o.notModified(path, tree.Hash.String()); err != nil {
+		return nil, err
+	}
+
+	nodes, err := l.listTree(repo, tree, path, true, modules)
+	return paginate(o, nodes), err
+}
+
+// IterateFiles yields every entry below path, each directory before its contents, without
+// Children. Subtrees are read as the walk reaches them.
+func (l *LocalGitAdapter) IterateFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) iter.Seq2[FileNode, error] {
+	return func(yield func(FileNode, error) bool) {
+		l.logger.Info("IterateFiles", "projectID", projectID, "path", path)
+		if err := validatePath(path); err != nil {
+			yield(FileNode{}, err)
+			return
+		}
+		o := newCallOptions(l.env.Branch, opts)
+		path = strings.Trim(path, "/")
+		if path == "." {
+			path = ""
+		}
+
+		repo, tree, err := l.openTree(projectID, o.branch)
+		if err != nil {
+			yield(FileNode{}, err)
+			return
+		}
+		if tree == nil {
+			return
+		}
+		modules := treeSubmodules(tree)
+		if path != "" {
+			if tree, err = tree.Tree(path); err != nil {
+				yield(FileNode{}, fmt.Errorf("failed to list contents at path '%s': %w", path, localError(err)))
+				return
+			}
+		}
+		l.walkTree(repo, tree, path, modules, yield)
+	}
+}
+
+// CommitFile creates or updates a file
+func (l *LocalGitAdapter) CommitFile(ctx context.Context, projectID uuid.UUID, path, content, message string, opts ...Option) error {
+	l.logger.Info("CommitFile", "projectID", projectID, "path", path, "message", message)
+	o := newCallOptions(l.env.Branch, opts)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	normalized, err := l.env.Normalization.apply(path, []byte(content))
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return err
+	}
+	if err := l.checkExpectedSHA(repo, o.branch, path, o.expectedSHA); err != nil {
+		return err
+	}
+
+	hash, err := l.writeBlob(repo, bytes.NewReader(normalized))
+	if err != nil {
+		return err
+	}
+
+	_, err = l.commitChanges(repo, o, message, map[string]*localChange{
+		path: {Hash: hash},
+	})
+	return err
+}
+
+// CommitFileBytes creates or updates a file with binary-safe content
+func (l *LocalGitAdapter) CommitFileBytes(ctx context.Context, projectID uuid.UUID, path string, content []byte, message string, opts ...Option) error {
+	return l.WriteFile(ctx, projectID, path, bytes.NewReader(content), message, opts...)
+}
+
+// WriteFile creates or updates a file from r.
+// go-git buffers a new object in memory before storing it, so r is read fully.
+func (l *LocalGitAdapter) WriteFile(ctx context.Context, projectID uuid.UUID, path string, r io.Reader, message string, opts ...Option) error {
+	l.logger.Info("WriteFile", "projectID", projectID, "path", path, "message", message)
+	o := newCallOptions(l.env.Branch, opts)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return err
+	}
+	if err := l.checkExpectedSHA(repo, o.branch, path, o.expectedSHA); err != nil {
+		return err
+	}
+
+	hash, err := l.writeBlob(repo, l.env.Limits.reader(path, r))
+	if err != nil {
+		return err
+	}
+
+	_, err = l.commitChanges(repo, o, message, map[string]*localChange{
+		path: {Hash: hash},
+	})
+	return err
+}
+
+// OpenFile streams a file at ref (branch, tag or SHA; empty for the default branch).
+// The caller must close the reader.
+func (l *LocalGitAdapter) OpenFile(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) (io.ReadCloser, error) {
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		ref = l.env.Branch
+	}
+	l.logger.Info("OpenFile", "projectID", projectID, "path", path, "ref", ref)
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := l.refTree(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	file, err := tree.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", localError(err))
+	}
+	return file.Reader()
+}
+
+// CommitFiles applies all changes in a single commit
+func (l *LocalGitAdapter) CommitFiles(ctx context.Context, projectID uuid.UUID, files []FileChange, message string, opts ...Option) error {
+	l.logger.Info("CommitFiles", "projectID", projectID, "files", len(files), "message", message)
+
+	if err := validateChanges(files); err != nil {
+		return err
+	}
+	files, err := l.env.Normalization.applyChanges(files)
+	if err != nil {
+		return err
+	}
+	return l.editTree(projectID, message, opts, changesEdit(files))
+}
+
+// NewChangeSet starts a change set applied as one commit by CommitFiles
+func (l *LocalGitAdapter) NewChangeSet(projectID uuid.UUID) *ChangeSet {
+	return newChangeSet(l, projectID)
+}
+
+// PlanChanges reports what CommitFiles would do with changes, without committing
+func (l *LocalGitAdapter) PlanChanges(ctx context.Context, projectID uuid.UUID, changes []FileChange, opts ...Option) (*Plan, error) {
+	return planChanges(ctx, l, l.logger, projectID, changes, opts)
+}
+
+// DeleteFile removes a single file
+func (l *LocalGitAdapter) DeleteFile(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	l.logger.Info("DeleteFile", "projectID", projectID, "path", path, "message", message)
+	o := newCallOptions(l.env.Branch, opts)
+
+	if err := validatePath(path); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repo, tree, err := l.openTree(projectID, o.branch)
+	if err != nil {
+		return err
+	}
+	if tree == nil {
+		return fmt.Errorf("file not found for deletion: %w", localError(object.ErrFileNotFound))
+	}
+	if _, err := tree.File(path); err != nil {
+		return fmt.Errorf("file not found for deletion: %w", localError(err))
+	}
+
+	_, err = l.commitChanges(repo, o, message, map[string]*localChange{path: nil})
+	return err
+}
+
+// DeletePath removes a file or a whole directory tree in a single commit
+func (l *LocalGitAdapter) DeletePath(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	return deletePath(ctx, l, l.logger, projectID, path, message, opts)
+}
+
+// SearchFiles returns every line of a text file on the branch that contains query
+func (l *LocalGitAdapter) SearchFiles(ctx context.Context, projectID uuid.UUID, query string, opts ...Option) ([]SearchMatch, error) {
+	return searchFiles(ctx, l, l.logger, projectID, query, opts)
+}
+
+// MoveFile renames a file in a single commit
+func (l *LocalGitAdapter) MoveFile(ctx context.Context, projectID uuid.UUID, oldPath, newPath, message string, opts ...Option) error {
+	if err := validatePaths(oldPath, newPath); err != nil {
+		return err
+	}
+	return moveFile(ctx, l, l.logger, projectID, oldPath, newPath, message, opts)
+}
+
+// CopyFile copies a file from one project repository into another
+func (l *LocalGitAdapter) CopyFile(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, srcPath, dstPath, message string) error {
+	return copyFiles(ctx, l, l.logger, srcProjectID, dstProjectID, map[string]string{srcPath: dstPath}, message)
+}
+
+// CopyFiles copies files (source path -> destination path) between project repositories in a single commit
+func (l *LocalGitAdapter) CopyFiles(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, paths map[string]string, message string) error {
+	return copyFiles(ctx, l, l.logger, srcProjectID, dstProjectID, paths, message)
+}
+
+// ImportArchive unpacks a tar or tar.gz stream and commits its files in a single commit
+func (l *LocalGitAdapter) ImportArchive(ctx context.Context, projectID uuid.UUID, r io.Reader, message string, opts ...Option) error {
+	return importArchive(ctx, l, l.logger, projectID, r, message, opts)
+}
+
+// CreateRepository initializes a new bare repository and returns its full name (owner/name).
+// With WithIdempotent, an existing repository is returned instead of failing. Of
+// WithRepositoryOptions, topics and visibility do not apply on disk.
+func (l *LocalGitAdapter) CreateRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) (string, error) {
+	l.logger.Info("CreateRepository", "projectID", projectID)
+	o := newCallOptions(l.env.Branch, opts)
+	name := cmp.Or(o.owner, l.env.Owner) + "/" + projectID.String()
+
+	// Render the presets first so an unknown one fails before anything is created
+	var presetFiles []FileNode
+	if len(o.repo.Presets) > 0 {
+		var err error
+		if presetFiles, err = PresetFiles(o.repo.Presets...); err != nil {
+			return "", err
+		}
+	}
+	var readme string
+	if o.repo.Readme != "" {
+		var err error
+		readme, err = renderReadme(o.repo.Readme, ReadmeData{
+			ProjectID:   projectID,
+			Name:        projectID.String(),
+			Owner:       cmp.Or(o.owner, l.env.Owner),
+			Description: o.repo.Description,
+			License:     o.repo.License,
+			Metadata:    o.repo.Metadata,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if o.repo.License != "" {
+		l.logger.Warn("License templates need Gitea, skipping LICENSE", "projectID", projectID, "license", o.repo.License)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repo, err := gogit.PlainInit(l.repoPath(projectID), true)
+	if errors.Is(err, gogit.ErrRepositoryAlreadyExists) && o.idempotent {
+		return name, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create local repository: %w", localError(err))
+	}
+
+	branch := cmp.Or(o.repo.DefaultBranch, l.env.Branch)
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName(branch))
+	if err := repo.Storer.SetReference(head); err != nil {
+		return "", fmt.Errorf("failed to set default branch: %w", err)
+	}
+	// Bare repositories keep their description in a file, as git init and gitweb do
+	if o.repo.Description != "" {
+		if err := os.WriteFile(filepath.Join(l.repoPath(projectID), "description"), []byte(o.repo.Description+"\n"), 0o644); err != nil {
+			return "", fmt.Errorf("failed to write repository description: %w", err)
+		}
+	}
+
+	autoInit := boolOr(o.repo.AutoInit, l.env.CreateRepoInit)
+	if autoInit || readme != "" {
+		// Mirror Gitea's auto-init so the branch is immediately usable
+		message := "Initial commit"
+		if !autoInit {
+			message = readmeMessage
+		}
+		hash, err := l.writeBlob(repo, strings.NewReader(cmp.Or(readme, fmt.Sprintf("# %s\n", projectID))))
+		if err != nil {
+			return "", err
+		}
+		_, err = l.commitChanges(repo, newCallOptions(branch, nil), message, map[string]*localChange{
+			"README.md": {Hash: hash, Mode: filemode.Regular},
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(presetFiles) > 0 {
+		changes := map[string]*localChange{}
+		for _, f := range presetFiles {
+			hash, err := l.writeBlob(repo, bytes.NewReader(f.Data()))
+			if err != nil {
+				return "", err
+			}
+			changes[f.Path] = &localChange{Hash: hash, Mode: filemode.Regular}
+		}
+		if _, err := l.commitChanges(repo, newCallOptions(branch, nil), presetMessage, changes); err != nil {
+			return "", fmt.Errorf("failed to add preset files: %w", err)
+		}
+	}
+
+	return name, nil
+}
+
+// Ping checks that the repository root is still a directory
+func (l *LocalGitAdapter) Ping(ctx context.Context) error {
+	info, err := os.Stat(l.env.Root)
+	if err != nil {
+		return fmt.Errorf("failed to reach local repository root: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("failed to reach local repository root: %s is not a directory", l.env.Root)
+	}
+	return nil
+}
+
+// ServerInfo describes the on-disk backend, which has no API limits
+func (l *LocalGitAdapter) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	if err := l.Ping(ctx); err != nil {
+		return nil, err
+	}
+	return &ServerInfo{Backend: "local"}, nil
+}
+
+// RepositoryExists reports whether the project repository exists on disk
+func (l *LocalGitAdapter) RepositoryExists(ctx context.Context, projectID uuid.UUID, opts ...Option) (bool, error) {
+	_, err := os.Stat(l.repoPath(projectID))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ScaffoldProjectFiles creates or updates multiple files, reporting the outcome per path
+func (l *LocalGitAdapter) ScaffoldProjectFiles(ctx context.Context, projectID uuid.UUID, files []FileNode) (*ScaffoldResult, error) {
+	return l.ScaffoldProjectFilesWithOptions(ctx, projectID, files, ScaffoldOptions{})
+}
+
+// ScaffoldProjectFilesWithOptions creates or updates multiple files using a bounded worker pool
+func (l *LocalGitAdapter) ScaffoldProjectFilesWithOptions(ctx context.Context, projectID uuid.UUID, files []FileNode, opts ScaffoldOptions) (*ScaffoldResult, error) {
+	if err := l.env.Limits.checkScaffold(len(files)); err != nil {
+		return nil, err
+	}
+	return scaffold(ctx, l, l.logger, projectID, files, opts, l.env.Normalization)
+}
+
+// ScaffoldFromTemplates renders fsys with data (see renderTemplates) and scaffolds the result
+func (l *LocalGitAdapter) ScaffoldFromTemplates(ctx context.Context, projectID uuid.UUID, fsys fs.FS, data any) (*ScaffoldResult, error) {
+	l.logger.Info("ScaffoldFromTemplates", "projectID", projectID)
+
+	files, err := renderTemplates(fsys, data)
+	if err != nil {
+		return nil, err
+	}
+	return l.ScaffoldProjectFiles(ctx, projectID, files)
+}
+
+// ScaffoldFromFS commits every file below root in fsys, e.g. an embed.FS or os.DirFS,
+// at the same path relative to root
+func (l *LocalGitAdapter) ScaffoldFromFS(ctx context.Context, projectID uuid.UUID, fsys fs.FS, root string) (*ScaffoldResult, error) {
+	l.logger.Info("ScaffoldFromFS", "projectID", projectID, "root", root)
+
+	files, err := subFiles(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	return l.ScaffoldProjectFiles(ctx, projectID, files)
+}
+
+// MergeScaffold upgrades files scaffolded from baseFiles to newFiles with a three-way merge per
+// file, so edits made in the repository survive. Conflicts are resolved according to strategy
+// and listed in the result; the merge is committed at once.
+func (l *LocalGitAdapter) MergeScaffold(ctx context.Context, projectID uuid.UUID, baseFiles, newFiles []FileNode, strategy ScaffoldMergeStrategy) (*ScaffoldMergeResult, error) {
+	return mergeScaffold(ctx, l, l.logger, projectID, baseFiles, newFiles, strategy)
+}
+
+// CreateBranch creates a branch from another branch. An empty from uses the configured branch.
+func (l *LocalGitAdapter) CreateBranch(ctx context.Context, projectID uuid.UUID, name, from string) (*Branch, error) {
+	if from == "" {
+		from = l.env.Branch
+	}
+	l.logger.Info("CreateBranch", "projectID", projectID, "branch", name, "from", from)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName(name), false); err == nil {
+		return nil, fmt.Errorf("failed to create branch '%s': %w", name, ErrConflict)
+	}
+	commit, err := l.branchCommit(repo, from)
+	if err != nil {
+		return nil, err
+	}
+	if commit == nil {
+		return nil, fmt.Errorf("failed to create branch '%s': branch '%s' has no commits: %w", name, from, ErrNotFound)
+	}
+
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(name), commit.Hash)
+	if err := repo.Storer.SetReference(ref); err != nil {
+		return nil, fmt.Errorf("failed to create branch '%s': %w", name, err)
+	}
+	return &Branch{Name: name, CommitSHA: commit.Hash.String(), UpdatedAt: commit.Committer.When}, nil
+}
+
+// DeleteBranch removes a branch
+func (l *LocalGitAdapter) DeleteBranch(ctx context.Context, projectID uuid.UUID, name string) error {
+	l.logger.Info("DeleteBranch", "projectID", projectID, "branch", name)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return err
+	}
+	refName := plumbing.NewBranchReferenceName(name)
+	if _, err := repo.Reference(refName, false); err != nil {
+		return fmt.Errorf("failed to delete branch '%s': %w", name, localError(err))
+	}
+	return repo.Storer.RemoveReference(refName)
+}
+
+// SetDefaultBranch points HEAD at branch, so clones check it out. Adapter calls keep using the
+// configured branch.
+func (l *LocalGitAdapter) SetDefaultBranch(ctx context.Context, projectID uuid.UUID, branch string) error {
+	l.logger.Info("SetDefaultBranch", "projectID", projectID, "branch", branch)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return err
+	}
+	refName := plumbing.NewBranchReferenceName(branch)
+	if _, err := repo.Reference(refName, false); err != nil {
+		return fmt.Errorf("failed to set default branch '%s': %w", branch, localError(err))
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, refName)); err != nil {
+		return fmt.Errorf("failed to set default branch '%s': %w", branch, err)
+	}
+	return nil
+}
+
+// Housekeep deletes the stale and merged branches policy selects and, with policy.GC, prunes
+// unreachable loose objects and repacks the rest into a single pack
+func (l *LocalGitAdapter) Housekeep(ctx context.Context, projectID uuid.UUID, policy HousekeepingPolicy) (*HousekeepingReport, error) {
+	l.logger.Info("Housekeep", "projectID", projectID, "gc", policy.GC, "staleAfter", policy.StaleAfter, "deleteMerged", policy.DeleteMerged)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := l.branchCommit(repo, l.env.Branch)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var remove []plumbing.ReferenceName
+	now := time.Now()
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		commit, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return fmt.Errorf("failed to read commit %s: %w", ref.Hash(), err)
+		}
+		branch := Branch{Name: ref.Name().Short(), CommitSHA: ref.Hash().String(), UpdatedAt: commit.Committer.When}
+		if !policy.expendable(branch, l.env.Branch) {
+			return nil
+		}
+		if policy.stale(branch, now) {
+			remove = append(remove, ref.Name())
+			return nil
+		}
+		if policy.DeleteMerged && base != nil {
+			ahead, err := countExclusive(commit, base)
+			if err != nil {
+				return err
+			}
+			if ahead == 0 {
+				remove = append(remove, ref.Name())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &HousekeepingReport{DeletedBranches: []string{}}
+	for _, name := range remove {
+		if !policy.DryRun {
+			if err := repo.Storer.RemoveReference(name); err != nil {
+				return report, fmt.Errorf("failed to delete branch '%s': %w", name.Short(), err)
+			}
+		}
+		report.DeletedBranches = append(report.DeletedBranches, name.Short())
+	}
+
+	if policy.GC && !policy.DryRun {
+		if err := repo.Prune(gogit.PruneOptions{Handler: repo.DeleteObject}); err != nil {
+			return report, fmt.Errorf("failed to prune objects: %w", err)
+		}
+		if err := repo.RepackObjects(&gogit.RepackConfig{}); err != nil {
+			return report, fmt.Errorf("failed to repack objects: %w", err)
+		}
+		report.GarbageCollected = true
+	}
+	return report, nil
+}
+
+// DownloadArchive writes the repository at ref (branch, tag or SHA) into w as a single archive.
+// Files are placed under a top-level directory named after the repository.
+func (l *LocalGitAdapter) DownloadArchive(ctx context.Context, projectID uuid.UUID, ref string, format ArchiveFormat, w io.Writer) error {
+	if ref == "" {
+		ref = l.env.Branch
+	}
+	l.logger.Info("DownloadArchive", "projectID", projectID, "ref", ref, "format", format)
+
+	repo, err := l.open(projectID)
+	if err != nil {
+		return err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return fmt.Errorf("failed to resolve ref '%s': %w", ref, localError(err))
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to read tree: %w", err)
+	}
+
+	archive, err := newArchiveWriter(w, format, commit.Committer.When)
+	if err != nil {
+		return err
+	}
+	prefix := projectID.String() + "/"
+	if err := archive.addDir(prefix); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to walk tree at '%s': %w", ref, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		switch entry.Mode {
+		case filemode.Dir:
+			err = archive.addDir(prefix + name)
+		case filemode.Regular, filemode.Deprecated, filemode.Executable, filemode.Symlink:
+			var content string
+			if content, err = l.readBlob(repo, entry.Hash); err != nil {
+				return fmt.Errorf("failed to read '%s': %w", name, err)
+			}
+			mode, _ := entry.Mode.ToOSFileMode()
+			err = archive.addFile(prefix+name, mode, []byte(content))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	return archive.Close()
+}
+
+// checkExpectedSHA enforces WithExpectedSHA against the branch tip. Callers must hold l.mu.
+func (l *LocalGitAdapter) checkExpectedSHA(repo *gogit.Repository, branch, path, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	commit, err := l.branchCommit(repo, branch)
+	if err != nil {
+		return err
+	}
+
+	current := ""
+	if commit != nil {
+		tree, err := commit.Tree()
+		if err != nil {
+			return fmt.Errorf("failed to read tree: %w", err)
+		}
+		if file, err := tree.File(path); err == nil {
+			current = file.Hash.String()
+		}
+	}
+	return checkExpectedSHA(path, expected, current)
+}
+
+// refTree resolves a branch, tag or commit SHA to its root tree
+func (l *LocalGitAdapter) refTree(repo *gogit.Repository, ref string) (*object.Tree, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref '%s': %w", ref, localError(err))
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree: %w", err)
+	}
+	return tree, nil
+}
+
+// fileNode reads a file and its content from tree
+func (l *LocalGitAdapter) fileNode(repo *gogit.Repository, tree *object.Tree, path string) (*FileNode, error) {
+	file, err := tree.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file contents: %w", localError(err))
+	}
+	content, err := l.readBlob(repo, file.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file contents: %w", err)
+	}
+
+	node := &FileNode{
+		Name: filepath.Base(path),
+		Path: path,
+		Type: FileTypeFile,
+		Mode: fileModeOf(file.Mode),
+		SHA:  file.Hash.String(),
+	}
+	node.setContent([]byte(content))
+	return node, nil
+}
+
+func (l *LocalGitAdapter) repoPath(projectID uuid.UUID) string {
+	return filepath.Join(l.env.Root, projectID.String()+".git")
+}
+
+func (l *LocalGitAdapter) open(projectID uuid.UUID) (*gogit.Repository, error) {
+	repo, err := gogit.PlainOpen(l.repoPath(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local repository %s: %w", projectID, localError(err))
+	}
+	return repo, nil
+}
+
+// openTree opens the repository and returns the tree at the branch tip.
+// The tree is nil when the branch has no commits yet.
+func (l *LocalGitAdapter) openTree(projectID uuid.UUID, branch string) (*gogit.Repository, *object.Tree, error) {
+	repo, err := l.open(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commit, err := l.branchCommit(repo, branch)
+	if err != nil || commit == nil {
+		return repo, nil, err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read tree: %w", err)
+	}
+	return repo, tree, nil
+}
+
+// branchCommit returns the commit at the branch tip, or nil for an unborn branch
+func (l *LocalGitAdapter) branchCommit(repo *gogit.Repository, branch string) (*object.Commit, error) {
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve branch '%s': %w", branch, err)
+	}
+
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", ref.Hash(), err)
+	}
+	return commit, nil
+}
+
+// listTree lists tree, found at base; modules maps submodule paths to URLs
+func (l *LocalGitAdapter) listTree(repo *gogit.Repository, tree *object.Tree, base string, isRecursive bool, modules map[string]string) ([]FileNode, error) {
+	var files []FileNode
+	for _, entry := range tree.Entries {
+		node := l.treeNode(repo, entry, base, modules)
+		if node.Type == FileTypeDir && isRecursive {
+			sub, err := object.GetTree(repo.Storer, entry.Hash)
+			if err != nil {
+				l.logger.Warn("Failed to list directory", "path", node.Path, "err", err)
+			} else if node.Children, err = l.listTree(repo, sub, node.Path, isRecursive, modules); err != nil {
+				l.logger.Warn("Failed to list directory", "path", node.Path, "err", err)
+			}
+		}
+		files = append(files, node)
+	}
+	return files, nil
+}
+
+// walkTree yields the entries of tree, found at base, depth first with each directory before
+// its contents. It returns false once yield asks to stop.
+func (l *LocalGitAdapter) walkTree(repo *gogit.Repository, tree *object.Tree, base string, modules map[string]string, yield func(FileNode, error) bool) bool {
+	for _, entry := range tree.Entries {
+		node := l.treeNode(repo, entry, base, modules)
+		if !yield(node, nil) {
+			return false
+		}
+		if node.Type != FileTypeDir {
+			continue
+		}
+		sub, err := object.GetTree(repo.Storer, entry.Hash)
+		if err != nil {
+			if !yield(FileNode{}, fmt.Errorf("failed to list directory '%s': %w", node.Path, err)) {
+				return false
+			}
+			continue
+		}
+		if !l.walkTree(repo, sub, node.Path, modules, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// treeNode describes a single tree entry found at base, without Children
+func (l *LocalGitAdapter) treeNode(repo *gogit.Repository, entry object.TreeEntry, base string, modules map[string]string) FileNode {
+	node := FileNode{
+		Name: entry.Name,
+		Path: joinPath(base, entry.Name),
+		Mode: fileModeOf(entry.Mode),
+		SHA:  entry.Hash.String(),
+	}
+	switch entry.Mode {
+	case filemode.Dir:
+		node.Type = FileTypeDir
+	case filemode.Symlink:
+		node.Type = FileTypeSymlink
+		if target, err := l.readBlob(repo, entry.Hash); err == nil {
+			node.Target = &target
+			node.Size = int64(len(target))
+		}
+	case filemode.Submodule:
+		node.Type = FileTypeSubmodule
+		node.SubmoduleURL = modules[node.Path]
+	default:
+		node.Type = FileTypeFile
+		if blob, err := repo.BlobObject(entry.Hash); err == nil {
+			node.Size = blob.Size
+		}
+	}
+	return node
+}
+
+func (l *LocalGitAdapter) readBlob(repo *gogit.Repository, hash plumbing.Hash) (string, error) {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return "", err
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	return string(b), err
+}
+
+func (l *LocalGitAdapter) writeBlob(repo *gogit.Repository, r io.Reader) (plumbing.Hash, error) {
+	return storeBlob(repo.Storer, r)
+}
+
+// storeBlob writes the content of r as a blob object to s
+func storeBlob(s storer.EncodedObjectStorer, r io.Reader) (plumbing.Hash, error) {
+	obj := s.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	hash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write blob: %w", err)
+	}
+	return hash, nil
+}
+
+// editTree commits the changes edit computes from the branch tip
+func (l *LocalGitAdapter) editTree(projectID uuid.UUID, message string, opts []Option, edit treeEdit) error {
+	o := newCallOptions(l.env.Branch, opts)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repo, tree, err := l.openTree(projectID, o.branch)
+	if err != nil {
+		return err
+	}
+	changes, err := edit(repo.Storer, tree)
+	if err != nil {
+		return err
+	}
+	_, err = l.commitChanges(repo, o, message, changes)
+	return err
+}
+
+// changesEdit returns an edit applying files, resolving each operation against root
+func changesEdit(files []FileChange) treeEdit {
+	return func(s storer.EncodedObjectStorer, root *object.Tree) (map[string]*localChange, error) {
+		changes := map[string]*localChange{}
+		for _, f := range files {
+			current := ""
+			if root != nil {
+				if file, err := root.File(sourcePath(f)); err == nil {
+					current = file.Hash.String()
+				}
+			}
+			operation, err := resolveOperation(f, current != "")
+			if err != nil {
+				return nil, err
+			}
+			if f.SHA != "" && operation != FileOperationCreate && f.SHA != current {
+				return nil, fmt.Errorf("file '%s' changed since it was read (expected %s, found %s): %w", sourcePath(f), f.SHA, current, ErrConflict)
+			}
+
+			if f.FromPath != "" {
+				changes[f.FromPath] = nil
+			}
+			if operation == FileOperationDelete {
+				changes[f.Path] = nil
+				continue
+			}
+			hash, err := storeBlob(s, bytes.NewReader(f.data()))
+			if err != nil {
+				return nil, err
+			}
+			mode, _ := f.Mode.gitMode() // validated by resolveOperation
+			if mode == 0 && f.FromPath != "" && root != nil {
+				if entry, err := root.FindEntry(f.FromPath); err == nil {
+					mode = entry.Mode
+				}
+			}
+			changes[f.Path] = &localChange{Hash: hash, Mode: mode}
+		}
+		return changes, nil
+	}
+}
+
+// isHeadBranch reports whether HEAD points at branch, e.g. a default branch set by WithRepositoryOptions
+func isHeadBranch(repo *gogit.Repository, branch string) bool {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	return err == nil && head.Target() == plumbing.NewBranchReferenceName(branch)
+}
+
+// commitChanges applies changes on top of the tip of o.branch as a single commit and advances the branch.
+// Callers must hold l.mu.
+func (l *LocalGitAdapter) commitChanges(repo *gogit.Repository, o callOptions, message string, changes map[string]*localChange) (plumbing.Hash, error) {
+	branch := o.branch
+	parent, err := l.branchCommit(repo, branch)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if parent == nil && branch != l.env.Branch && !isHeadBranch(repo, branch) {
+		// Only the default branch may be born by a commit; others come from CreateBranch
+		return plumbing.ZeroHash, fmt.Errorf("branch '%s': %w", branch, ErrNotFound)
+	}
+
+	if err := l.env.Limits.checkChanges(repo.Storer, changes); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := l.checkRepoSize(repo); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	var base *object.Tree
+	var parents []plumbing.Hash
+	if parent != nil {
+		if base, err = parent.Tree(); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read tree: %w", err)
+		}
+		parents = append(parents, parent.Hash)
+	}
+
+	committer := object.Signature{Name: l.env.IdName, Email: l.env.IdMail, When: time.Now()}
+	author := committer
+	if o.authorName != "" {
+		author.Name, author.Email = o.authorName, o.authorEmail
+	}
+	o.format = l.env.CommitFormat
+	hash, err := writeCommit(repo.Storer, base, parents, changes, author, committer, o.message(message), l.signer)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), hash)
+	if err := repo.Storer.SetReference(ref); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to update branch '%s': %w", branch, err)
+	}
+	o.committed(hash.String())
+	return hash, nil
+}
+
+// checkRepoSize enforces Limits.MaxRepoSize on the storage of repo, which already holds the blobs
+// about to be committed
+func (l *LocalGitAdapter) checkRepoSize(repo *gogit.Repository) error {
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if l.env.Limits.MaxRepoSize <= 0 || !ok {
+		return nil
+	}
+	size, err := dirSize(storage.Filesystem().Root())
+	if err != nil {
+		return fmt.Errorf("failed to check repository size: %w", err)
+	}
+	return l.env.Limits.checkRepo(size)
+}
+
+// writeCommit stores a commit of base with changes applied, without moving any reference.
+// The commit is signed when signer is not nil.
+func writeCommit(s storer.EncodedObjectStorer, base *object.Tree, parents []plumbing.Hash, changes map[string]*localChange, author, committer object.Signature, message string, signer gogit.Signer) (plumbing.Hash, error) {
+	treeHash, _, err := buildTree(s, base, changes)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to build tree: %w", err)
+	}
+
+	commit := &object.Commit{
+		Author:       author,
+		Committer:    committer,
+		Message:      message,
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+	}
+	if signer != nil {
+		unsigned := s.NewEncodedObject()
+		if err := commit.EncodeWithoutSignature(unsigned); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		r, err := unsigned.Reader()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		signature, err := signer.Sign(r)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		commit.PGPSignature = string(signature)
+	}
+	obj := s.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	hash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write commit: %w", err)
+	}
+	return hash, nil
+}
+
+// buildTree writes a new tree derived from base with changes applied (paths relative to base).
+// It reports whether the resulting tree is empty so callers can prune empty directories.
+func buildTree(s storer.EncodedObjectStorer, base *object.Tree, changes map[string]*localChange) (plumbing.Hash, bool, error) {
+	entries := map[string]object.TreeEntry{}
+	if base != nil {
+		for _, e := range base.Entries {
+			entries[e.Name] = e
+		}
+	}
+
+	nested := map[string]map[string]*localChange{}
+	for path, change := range changes {
+		name, rest, isNested := strings.Cut(strings.Trim(path, "/"), "/")
+		if isNested {
+			if nested[name] == nil {
+				nested[name] = map[string]*localChange{}
+			}
+			nested[name][rest] = change
+			continue
+		}
+		if change == nil {
+			delete(entries, name)
+			continue
+		}
+		mode := change.Mode
+		if mode == 0 {
+			mode = filemode.Regular
+			if e, ok := entries[name]; ok && e.Mode == filemode.Executable {
+				mode = filemode.Executable
+			}
+		}
+		entries[name] = object.TreeEntry{Name: name, Mode: mode, Hash: change.Hash}
+	}
+
+	for name, sub := range nested {
+		var child *object.Tree
+		if e, ok := entries[name]; ok && e.Mode == filemode.Dir {
+			var err error
+			if child, err = object.GetTree(s, e.Hash); err != nil {
+				return plumbing.ZeroHash, false, err
+			}
+		}
+		hash, empty, err := buildTree(s, child, sub)
+		if err != nil {
+			return plumbing.ZeroHash, false, err
+		}
+		if empty {
+			// Git does not track empty directories
+			delete(entries, name)
+			continue
+		}
+		entries[name] = object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: hash}
+	}
+
+	tree := &object.Tree{}
+	for _, e := range entries {
+		tree.Entries = append(tree.Entries, e)
+	}
+	// Git orders entries as if directory names had a trailing slash
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return treeSortKey(tree.Entries[i]) < treeSortKey(tree.Entries[j])
+	})
+
+	obj := s.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, false, err
+	}
+	hash, err := s.SetEncodedObject(obj)
+	return hash, len(tree.Entries) == 0, err
+}
+
+func treeSortKey(e object.TreeEntry) string {
+	if e.Mode == filemode.Dir {
+		return e.Name + "/"
+	}
+	return e.Name
+}
+
+func joinPath(base, name string) string {
+	if base == "" {
+		return name
+	}
+	return base + "/" + name
+}
+
+// localError tags go-git lookup failures with ErrNotFound or ErrConflict
+func localError(err error) error {
+	switch {
+	case errors.Is(err, object.ErrFileNotFound),
+		errors.Is(err, object.ErrDirectoryNotFound),
+		errors.Is(err, object.ErrEntryNotFound),
+		errors.Is(err, plumbing.ErrReferenceNotFound),
+		errors.Is(err, plumbing.ErrObjectNotFound),
+		errors.Is(err, gogit.ErrRepositoryNotExists):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case errors.Is(err, gogit.ErrRepositoryAlreadyExists):
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return err
+}
@@ -0,0 +1,654 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var _ Adapter = (*MemoryAdapter)(nil)
+
+// NewMemoryAdapter returns an empty in-memory adapter using the default owner and branch
+func NewMemoryAdapter() *MemoryAdapter {
+	return &MemoryAdapter{
+		logger: slog.Default(),
+		owner:  "zaminebazi",
+		branch: "main",
+		repos:  map[uuid.UUID]map[string]map[string]string{},
+		execs:  map[uuid.UUID]map[string]map[string]bool{},
+	}
+}
+
+// SetLogger replaces the logger, e.g. slog.New(slog.DiscardHandler) to silence the adapter
+func (m *MemoryAdapter) SetLogger(logger *slog.Logger) {
+	m.logger = logger
+}
+
+// GetFile retrieves a file with its simulated blob SHA
+func (m *MemoryAdapter) GetFile(ctx context.Context, projectID uuid.UUID, filePath string, opts ...Option) (*FileNode, error) {
+	m.logger.Info("GetFile", "projectID", projectID, "path", filePath)
+
+	if err := validatePath(filePath); err != nil {
+		return nil, err
+	}
+
+	o := newCallOptions(m.branch, opts)
+	branch := o.branch
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files, err := m.repo(projectID, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	content, ok := files[filePath]
+	if !ok {
+		return nil, fmt.Errorf("failed to get file contents: '%s': %w", filePath, ErrNotFound)
+	}
+	if err := o.notModified(filePath, blobSHA(content)); err != nil {
+		return nil, err
+	}
+
+	node := &FileNode{
+		Name: path.Base(filePath),
+		Path: filePath,
+		Type: FileTypeFile,
+		Mode: memoryMode(m.execs[projectID][branch], filePath),
+		SHA:  blobSHA(content),
+	}
+	node.setContent([]byte(content))
+	return node, nil
+}
+
+// GetFiles retrieves several files from one snapshot. Files that could be read are returned
+// even when others fail; the error joins the per-path failures.
+func (m *MemoryAdapter) GetFiles(ctx context.Context, projectID uuid.UUID, paths []string, opts ...Option) (map[string]*FileNode, error) {
+	m.logger.Info("GetFiles", "projectID", projectID, "files", len(paths))
+
+	if err := validatePaths(paths...); err != nil {
+		return nil, err
+	}
+
+	branch := newCallOptions(m.branch, opts).branch
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files, err := m.repo(projectID, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*FileNode, len(paths))
+	var failed []error
+	for _, filePath := range paths {
+		content, ok := files[filePath]
+		if !ok {
+			failed = append(failed, fmt.Errorf("path %s: %w", filePath, ErrNotFound))
+			continue
+		}
+		node := &FileNode{
+			Name: path.Base(filePath),
+			Path: filePath,
+			Type: FileTypeFile,
+			Mode: memoryMode(m.execs[projectID][branch], filePath),
+			SHA:  blobSHA(content),
+		}
+		node.setContent([]byte(content))
+		nodes[filePath] = node
+	}
+	return nodes, errors.Join(failed...)
+}
+
+// StatFile returns the type, SHA and size of a file or directory without its content
+func (m *MemoryAdapter) StatFile(ctx context.Context, projectID uuid.UUID, filePath string, opts ...Option) (*FileNode, error) {
+	m.logger.Info("StatFile", "projectID", projectID, "path", filePath)
+
+	if err := validatePath(filePath); err != nil {
+		return nil, err
+	}
+
+	branch := newCallOptions(m.branch, opts).branch
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files, err := m.repo(projectID, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	filePath = strings.Trim(filePath, "/")
+	if filePath == "" {
+		return &FileNode{Type: FileTypeDir, Mode: FileModeDir, SHA: dirSHA(m.listDir(files, m.execs[projectID][branch], "", true))}, nil
+	}
+	dir := path.Dir(filePath)
+	if dir == "." {
+		dir = ""
+	}
+	for _, node := range m.listDir(files, m.execs[projectID][branch], dir, false) {
+		if node.Path == filePath {
+			return &node, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to stat '%s': %w", filePath, ErrNotFound)
+}
+
+// ListFiles retrieves files. If path is empty, lists root.
+// If path not set ("", "."), it recursively fetches all files and directories.
+func (m *MemoryAdapter) ListFiles(ctx context.Context, projectID uuid.UUID, dir string, opts ...Option) ([]FileNode, error) {
+	m.logger.Info("ListFiles", "projectID", projectID, "path", dir)
+
+	if err := validatePath(dir); err != nil {
+		return nil, err
+	}
+
+	isRecursive := false
+	switch dir {
+	case ".", "":
+		dir = ""
+		isRecursive = true
+	}
+
+	o := newCallOptions(m.branch, opts)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files, err := m.repo(projectID, o.branch)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := m.listDir(files, m.execs[projectID][o.branch], dir, isRecursive)
+	if dir != "" && nodes == nil {
+		return nil, fmt.Errorf("failed to list contents at path '%s': %w", dir, ErrNotFound)
+	}
+	if o.ifNoneMatch != "" {
+		if err := o.notModified(dir, dirSHA(m.listDir(files, m.execs[projectID][o.branch], dir, true))); err != nil {
+			return nil, err
+		}
+	}
+	return paginate(o, nodes), nil
+}
+
+// ListFilesRecursive lists everything below dir, populating Children for directories
+func (m *MemoryAdapter) ListFilesRecursive(ctx context.Context, projectID uuid.UUID, dir string, opts ...Option) ([]FileNode, error) {
+	m.logger.Info("ListFilesRecursive", "projectID", projectID, "path", dir)
+
+	if err := validatePath(dir); err != nil {
+		return nil, err
+	}
+
+	dir = strings.Trim(dir, "/")
+	if dir == "." {
+		dir = ""
+	}
+
+	o := newCallOptions(m.branch, opts)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files, err := m.repo(projectID, o.branch)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := m.listDir(files, m.execs[projectID][o.branch], dir, true)
+	if dir != "" && nodes == nil {
+		return nil, fmt.Errorf("failed to list contents at path '%s': %w", dir, ErrNotFound)
+	}
+	if o.ifNoneMatch != "" {
+		if err := o.notModified(dir, dirSHA(m.listDir(files, m.execs[projectID][o.branch], dir, true))); err != nil {
+			return nil, err
+		}
+	}
+	return paginate(o, nodes), nil
+}
+
+// IterateFiles yields every entry below dir, each directory before its contents, without
+// Children. The listing is a snapshot taken when iteration starts.
+func (m *MemoryAdapter) IterateFiles(ctx context.Context, projectID uuid.UUID, dir string, opts ...Option) iter.Seq2[FileNode, error] {
+	return func(yield func(FileNode, error) bool) {
+		nodes, err := m.ListFilesRecursive(ctx, projectID, dir, opts...)
+		if err != nil {
+			yield(FileNode{}, err)
+			return
+		}
+		var walk func([]FileNode) bool
+		walk = func(nodes []FileNode) bool {
+			for _, n := range nodes {
+				children := n.Children
+				n.Children = nil
+				if !yield(n, nil) || !walk(children) {
+					return false
+				}
+			}
+			return true
+		}
+		walk(nodes)
+	}
+}
+
+// CommitFile creates or updates a file
+func (m *MemoryAdapter) CommitFile(ctx context.Context, projectID uuid.UUID, filePath, content, message string, opts ...Option) error {
+	m.logger.Info("CommitFile", "projectID", projectID, "path", filePath, "message", message)
+
+	if err := validatePath(filePath); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	o := newCallOptions(m.branch, opts)
+	files, err := m.repo(projectID, o.branch)
+	if err != nil {
+		return err
+	}
+
+	current := ""
+	if existing, ok := files[filePath]; ok {
+		current = blobSHA(existing)
+	}
+	if err := checkExpectedSHA(filePath, o.expectedSHA, current); err != nil {
+		return err
+	}
+
+	files[filePath] = content
+	return nil
+}
+
+// CommitFileBytes creates or updates a file with binary-safe content
+func (m *MemoryAdapter) CommitFileBytes(ctx context.Context, projectID uuid.UUID, filePath string, content []byte, message string, opts ...Option) error {
+	return m.CommitFile(ctx, projectID, filePath, string(content), message, opts...)
+}
+
+// WriteFile creates or updates a file from r
+func (m *MemoryAdapter) WriteFile(ctx context.Context, projectID uuid.UUID, filePath string, r io.Reader, message string, opts ...Option) error {
+	if err := validatePath(filePath); err != nil {
+		return err
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+	return m.CommitFile(ctx, projectID, filePath, string(content), message, opts...)
+}
+
+// OpenFile returns a reader over a file at ref (a branch; empty for the default branch)
+func (m *MemoryAdapter) OpenFile(ctx context.Context, projectID uuid.UUID, filePath, ref string, opts ...Option) (io.ReadCloser, error) {
+	file, err := m.GetFile(ctx, projectID, filePath, append(slices.Clone(opts), WithBranch(ref))...)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(strings.NewReader(*file.Content)), nil
+}
+
+// CommitFiles applies all changes at once; nothing is written if any change is invalid
+func (m *MemoryAdapter) CommitFiles(ctx context.Context, projectID uuid.UUID, changes []FileChange, message string, opts ...Option) error {
+	m.logger.Info("CommitFiles", "projectID", projectID, "files", len(changes), "message", message)
+
+	if err := validateChanges(changes); err != nil {
+		return err
+	}
+
+	branch := newCallOptions(m.branch, opts).branch
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	files, err := m.repo(projectID, branch)
+	if err != nil {
+		return err
+	}
+	execs := m.executables(projectID, branch)
+
+	operations := make([]FileOperation, len(changes))
+	for i, f := range changes {
+		_, exists := files[sourcePath(f)]
+		if operations[i], err = resolveOperation(f, exists); err != nil {
+			return err
+		}
+	}
+
+	for i, f := range changes {
+		executable := execs[sourcePath(f)]
+		if f.FromPath != "" {
+			delete(files, f.FromPath)
+			delete(execs, f.FromPath)
+		}
+		if operations[i] == FileOperationDelete {
+			delete(files, f.Path)
+			delete(execs, f.Path)
+			continue
+		}
+		files[f.Path] = string(f.data())
+		if f.Mode != "" {
+			executable = f.Mode == FileModeExecutable
+		}
+		if executable {
+			execs[f.Path] = true
+		} else {
+			delete(execs, f.Path)
+		}
+	}
+	return nil
+}
+
+// NewChangeSet starts a change set applied as one commit by CommitFiles
+func (m *MemoryAdapter) NewChangeSet(projectID uuid.UUID) *ChangeSet {
+	return newChangeSet(m, projectID)
+}
+
+// PlanChanges reports what CommitFiles would do with changes, without committing
+func (m *MemoryAdapter) PlanChanges(ctx context.Context, projectID uuid.UUID, changes []FileChange, opts ...Option) (*Plan, error) {
+	return planChanges(ctx, m, m.logger, projectID, changes, opts)
+}
+
+// DeleteFile removes a single file
+func (m *MemoryAdapter) DeleteFile(ctx context.Context, projectID uuid.UUID, filePath, message string, opts ...Option) error {
+	m.logger.Info("DeleteFile", "projectID", projectID, "path", filePath, "message", message)
+
+	if err := validatePath(filePath); err != nil {
+		return err
+	}
+
+	branch := newCallOptions(m.branch, opts).branch
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	files, err := m.repo(projectID, branch)
+	if err != nil {
+		return err
+	}
+	if _, ok := files[filePath]; !ok {
+		return fmt.Errorf("file not found for deletion: '%s': %w", filePath, ErrNotFound)
+	}
+
+	delete(files, filePath)
+	delete(m.execs[projectID][branch], filePath)
+	return nil
+}
+
+// DeletePath removes a file or a whole directory tree in a single commit
+func (m *MemoryAdapter) DeletePath(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	return deletePath(ctx, m, m.logger, projectID, path, message, opts)
+}
+
+// SearchFiles returns every line of a text file on the branch that contains query
+func (m *MemoryAdapter) SearchFiles(ctx context.Context, projectID uuid.UUID, query string, opts ...Option) ([]SearchMatch, error) {
+	return searchFiles(ctx, m, m.logger, projectID, query, opts)
+}
+
+// MoveFile renames a file in a single commit
+func (m *MemoryAdapter) MoveFile(ctx context.Context, projectID uuid.UUID, oldPath, newPath, message string, opts ...Option) error {
+	if err := validatePaths(oldPath, newPath); err != nil {
+		return err
+	}
+	return moveFile(ctx, m, m.logger, projectID, oldPath, newPath, message, opts)
+}
+
+// CopyFile copies a file from one project repository into another
+func (m *MemoryAdapter) CopyFile(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, srcPath, dstPath, message string) error {
+	return copyFiles(ctx, m, m.logger, srcProjectID, dstProjectID, map[string]string{srcPath: dstPath}, message)
+}
+
+// CopyFiles copies files (source path -> destination path) between project repositories in a single commit
+func (m *MemoryAdapter) CopyFiles(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, paths map[string]string, message string) error {
+	return copyFiles(ctx, m, m.logger, srcProjectID, dstProjectID, paths, message)
+}
+
+// ImportArchive unpacks a tar or tar.gz stream and commits its files in a single commit
+func (m *MemoryAdapter) ImportArchive(ctx context.Context, projectID uuid.UUID, r io.Reader, message string, opts ...Option) error {
+	return importArchive(ctx, m, m.logger, projectID, r, message, opts)
+}
+
+// CreateRepository registers an empty repository and returns its full name (owner/name).
+// With WithIdempotent, an existing repository is returned instead of failing.
+func (m *MemoryAdapter) CreateRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) (string, error) {
+	m.logger.Info("CreateRepository", "projectID", projectID)
+	o := newCallOptions(m.branch, opts)
+	name := cmp.Or(o.owner, m.owner) + "/" + projectID.String()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.repos[projectID]; ok {
+		if o.idempotent {
+			return name, nil
+		}
+		return "", fmt.Errorf("failed to create memory repository %s: %w", projectID, ErrConflict)
+	}
+
+	files := map[string]string{}
+	if len(o.repo.Presets) > 0 {
+		generated, err := PresetFiles(o.repo.Presets...)
+		if err != nil {
+			return "", err
+		}
+		for _, f := range generated {
+			files[f.Path] = *f.Content
+		}
+	}
+	if o.repo.Readme != "" {
+		readme, err := renderReadme(o.repo.Readme, ReadmeData{
+			ProjectID:   projectID,
+			Name:        projectID.String(),
+			Owner:       cmp.Or(o.owner, m.owner),
+			Description: o.repo.Description,
+			License:     o.repo.License,
+			Metadata:    o.repo.Metadata,
+		})
+		if err != nil {
+			return "", err
+		}
+		files["README.md"] = readme
+	}
+	if o.repo.License != "" {
+		m.logger.Warn("License templates need Gitea, skipping LICENSE", "projectID", projectID, "license", o.repo.License)
+	}
+	m.repos[projectID] = map[string]map[string]string{m.branch: files}
+	return name, nil
+}
+
+// Ping always succeeds
+func (m *MemoryAdapter) Ping(ctx context.Context) error {
+	return nil
+}
+
+// ServerInfo describes the in-memory backend
+func (m *MemoryAdapter) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	return &ServerInfo{Backend: "memory"}, nil
+}
+
+// RepositoryExists reports whether the project repository has been created
+func (m *MemoryAdapter) RepositoryExists(ctx context.Context, projectID uuid.UUID, opts ...Option) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.repos[projectID]
+	return ok, nil
+}
+
+// ScaffoldProjectFiles creates or updates multiple files, reporting the outcome per path
+func (m *MemoryAdapter) ScaffoldProjectFiles(ctx context.Context, projectID uuid.UUID, files []FileNode) (*ScaffoldResult, error) {
+	return m.ScaffoldProjectFilesWithOptions(ctx, projectID, files, ScaffoldOptions{})
+}
+
+// ScaffoldProjectFilesWithOptions creates or updates multiple files using a bounded worker pool
+func (m *MemoryAdapter) ScaffoldProjectFilesWithOptions(ctx context.Context, projectID uuid.UUID, files []FileNode, opts ScaffoldOptions) (*ScaffoldResult, error) {
+	return scaffold(ctx, m, m.logger, projectID, files, opts, Normalization{})
+}
+
+// ScaffoldFromTemplates renders fsys with data (see renderTemplates) and scaffolds the result
+func (m *MemoryAdapter) ScaffoldFromTemplates(ctx context.Context, projectID uuid.UUID, fsys fs.FS, data any) (*ScaffoldResult, error) {
+	m.logger.Info("ScaffoldFromTemplates", "projectID", projectID)
+
+	files, err := renderTemplates(fsys, data)
+	if err != nil {
+		return nil, err
+	}
+	return m.ScaffoldProjectFiles(ctx, projectID, files)
+}
+
+// ScaffoldFromFS commits every file below root in fsys, e.g. an embed.FS or os.DirFS,
+// at the same path relative to root
+func (m *MemoryAdapter) ScaffoldFromFS(ctx context.Context, projectID uuid.UUID, fsys fs.FS, root string) (*ScaffoldResult, error) {
+	m.logger.Info("ScaffoldFromFS", "projectID", projectID, "root", root)
+
+	files, err := subFiles(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	return m.ScaffoldProjectFiles(ctx, projectID, files)
+}
+
+// MergeScaffold upgrades files scaffolded from baseFiles to newFiles with a three-way merge per
+// file, so edits made in the repository survive. Conflicts are resolved according to strategy
+// and listed in the result; the merge is committed at once.
+func (m *MemoryAdapter) MergeScaffold(ctx context.Context, projectID uuid.UUID, baseFiles, newFiles []FileNode, strategy ScaffoldMergeStrategy) (*ScaffoldMergeResult, error) {
+	return mergeScaffold(ctx, m, m.logger, projectID, baseFiles, newFiles, strategy)
+}
+
+// CreateBranch copies another branch. An empty from uses the default branch.
+func (m *MemoryAdapter) CreateBranch(ctx context.Context, projectID uuid.UUID, name, from string) (*Branch, error) {
+	if from == "" {
+		from = m.branch
+	}
+	m.logger.Info("CreateBranch", "projectID", projectID, "branch", name, "from", from)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	source, err := m.repo(projectID, from)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := m.repos[projectID][name]; ok {
+		return nil, fmt.Errorf("failed to create branch '%s': %w", name, ErrConflict)
+	}
+
+	files := make(map[string]string, len(source))
+	for p, content := range source {
+		files[p] = content
+	}
+	m.repos[projectID][name] = files
+	maps.Copy(m.executables(projectID, name), m.execs[projectID][from])
+	return &Branch{Name: name}, nil
+}
+
+// DeleteBranch removes a branch
+func (m *MemoryAdapter) DeleteBranch(ctx context.Context, projectID uuid.UUID, name string) error {
+	m.logger.Info("DeleteBranch", "projectID", projectID, "branch", name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.repo(projectID, name); err != nil {
+		return err
+	}
+	delete(m.repos[projectID], name)
+	delete(m.execs[projectID], name)
+	return nil
+}
+
+// repo returns the file map of a project branch. Callers must hold m.mu.
+func (m *MemoryAdapter) repo(projectID uuid.UUID, branch string) (map[string]string, error) {
+	branches, ok := m.repos[projectID]
+	if !ok {
+		return nil, fmt.Errorf("memory repository %s: %w", projectID, ErrNotFound)
+	}
+	files, ok := branches[branch]
+	if !ok {
+		return nil, fmt.Errorf("branch '%s' in memory repository %s: %w", branch, projectID, ErrNotFound)
+	}
+	return files, nil
+}
+
+// dirSHA simulates the SHA of a directory from its sorted children
+func dirSHA(children []FileNode) string {
+	var sum strings.Builder
+	for _, child := range children {
+		sum.WriteString(child.Name + child.SHA)
+	}
+	return blobSHA(sum.String())
+}
+
+// listDir derives directory entries from the flat path map. Directory SHAs are
+// simulated from their children so they change whenever anything below them does.
+func (m *MemoryAdapter) listDir(files map[string]string, execs map[string]bool, dir string, isRecursive bool) []FileNode {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+
+	seenDirs := map[string]bool{}
+	var nodes []FileNode
+	for p, content := range files {
+		if !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		name, _, isNested := strings.Cut(strings.TrimPrefix(p, prefix), "/")
+		if !isNested {
+			nodes = append(nodes, FileNode{
+				Name: name,
+				Path: p,
+				Type: FileTypeFile,
+				Mode: memoryMode(execs, p),
+				SHA:  blobSHA(content),
+				Size: int64(len(content)),
+			})
+			continue
+		}
+		if seenDirs[name] {
+			continue
+		}
+		seenDirs[name] = true
+
+		node := FileNode{Name: name, Path: prefix + name, Type: FileTypeDir, Mode: FileModeDir}
+		children := m.listDir(files, execs, node.Path, true)
+		node.SHA = dirSHA(children)
+		if isRecursive {
+			node.Children = children
+		}
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// executables returns the set of executable paths on a branch, creating it if needed.
+// Callers must hold m.mu for writing.
+func (m *MemoryAdapter) executables(projectID uuid.UUID, branch string) map[string]bool {
+	if m.execs[projectID] == nil {
+		m.execs[projectID] = map[string]map[string]bool{}
+	}
+	if m.execs[projectID][branch] == nil {
+		m.execs[projectID][branch] = map[string]bool{}
+	}
+	return m.execs[projectID][branch]
+}
+
+// memoryMode reports the mode of a file given its branch's executable set
+func memoryMode(execs map[string]bool, p string) FileMode {
+	if execs[p] {
+		return FileModeExecutable
+	}
+	return FileModeRegular
+}
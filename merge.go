@@ -0,0 +1,262 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/google/uuid"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// mergeScaffoldMessage is the commit message of MergeScaffold
+const mergeScaffoldMessage = "Merge scaffold update"
+
+// mergeHunk replaces lines [start, end) of the base version with lines
+type mergeHunk struct {
+	start, end int
+	lines      []string
+}
+
+// mergeScaffold upgrades the files generated from base to next, keeping edits made in the
+// repository since. Every path is resolved first and the result is committed at once.
+func mergeScaffold(ctx context.Context, a Adapter, logger *slog.Logger, projectID uuid.UUID, base, next []FileNode, strategy ScaffoldMergeStrategy) (*ScaffoldMergeResult, error) {
+	if strategy == "" {
+		strategy = ScaffoldMergeReport
+	}
+	logger.Info("MergeScaffold", "projectID", projectID, "base", len(base), "files", len(next), "strategy", strategy)
+	switch strategy {
+	case ScaffoldMergeReport, ScaffoldMergeMarkers, ScaffoldMergeOurs, ScaffoldMergeTheirs:
+	default:
+		return nil, fmt.Errorf("unknown scaffold merge strategy '%s'", strategy)
+	}
+
+	bases := nodesByPath(base)
+	nexts := nodesByPath(next)
+	existing, err := blobIndex(ctx, a, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository files: %w", err)
+	}
+	var paths, present []string
+	for p := range mergePaths(bases, nexts) {
+		paths = append(paths, p)
+		if _, ok := existing[p]; ok {
+			present = append(present, p)
+		}
+	}
+	sort.Strings(paths)
+	current := map[string]*FileNode{}
+	if len(present) > 0 {
+		if current, err = a.GetFiles(ctx, projectID, present); err != nil {
+			return nil, fmt.Errorf("failed to read repository files: %w", err)
+		}
+	}
+
+	result := &ScaffoldMergeResult{}
+	var changes []FileChange
+	for _, p := range paths {
+		b, inBase := bases[p]
+		n, inNext := nexts[p]
+		r, inRepo := current[p]
+
+		switch {
+		case !inNext:
+			// Removed from the template: drop the file unless the repository edited it
+			switch {
+			case !inRepo:
+				result.Unchanged = append(result.Unchanged, p)
+			case string(r.Data()) == string(b.Data()) || strategy == ScaffoldMergeTheirs:
+				changes = append(changes, FileChange{Operation: FileOperationDelete, Path: p, SHA: r.SHA})
+				result.Deleted = append(result.Deleted, p)
+			default:
+				result.Conflicts = append(result.Conflicts, p)
+			}
+		case !inRepo:
+			// Never created, or deleted in the repository after scaffolding
+			switch {
+			case !inBase:
+				changes = append(changes, FileChange{Path: p, Bytes: n.Data(), Mode: n.Mode})
+				result.Created = append(result.Created, p)
+			case string(n.Data()) == string(b.Data()):
+				result.Unchanged = append(result.Unchanged, p)
+			case strategy == ScaffoldMergeTheirs:
+				changes = append(changes, FileChange{Path: p, Bytes: n.Data(), Mode: n.Mode})
+				result.Created = append(result.Created, p)
+			default:
+				result.Conflicts = append(result.Conflicts, p)
+			}
+		default:
+			baseData := ""
+			if inBase {
+				baseData = string(b.Data())
+			}
+			ours, theirs := string(r.Data()), string(n.Data())
+			switch {
+			case ours == theirs || (inBase && theirs == baseData):
+				result.Unchanged = append(result.Unchanged, p)
+				continue
+			case inBase && ours == baseData:
+				changes = append(changes, FileChange{Path: p, Bytes: n.Data(), SHA: r.SHA, Mode: n.Mode})
+				result.Updated = append(result.Updated, p)
+				continue
+			}
+
+			merged, conflict := ours, true
+			if !r.IsBinary && !n.IsBinary && (!inBase || !b.IsBinary) {
+				merged, conflict = mergeText(baseData, ours, theirs, strategy)
+			} else if strategy == ScaffoldMergeTheirs {
+				merged = theirs
+			}
+			if conflict {
+				result.Conflicts = append(result.Conflicts, p)
+				if strategy == ScaffoldMergeReport || merged == ours {
+					continue
+				}
+			} else {
+				result.Updated = append(result.Updated, p)
+			}
+			changes = append(changes, FileChange{Path: p, Bytes: []byte(merged), SHA: r.SHA, Mode: n.Mode})
+		}
+	}
+
+	logger.Info("Merged scaffold", "projectID", projectID, "created", len(result.Created), "updated", len(result.Updated),
+		"deleted", len(result.Deleted), "conflicts", len(result.Conflicts))
+	if len(changes) == 0 {
+		return result, nil
+	}
+	if err := a.CommitFiles(ctx, projectID, changes, mergeScaffoldMessage); err != nil {
+		return nil, fmt.Errorf("failed to commit scaffold merge: %w", err)
+	}
+	return result, nil
+}
+
+// mergeText merges the line changes from base to ours and from base to theirs. Overlapping
+// or adjacent changes that differ conflict and are resolved according to strategy.
+func mergeText(base, ours, theirs string, strategy ScaffoldMergeStrategy) (string, bool) {
+	baseLines := splitLines(base)
+	a, b := lineHunks(base, ours), lineHunks(base, theirs)
+
+	var out strings.Builder
+	conflict := false
+	pos, i, j := 0, 0, 0
+	for i < len(a) || j < len(b) {
+		// Start a region at the earliest hunk and grow it with every hunk that touches it
+		start := len(baseLines)
+		if i < len(a) {
+			start = a[i].start
+		}
+		if j < len(b) {
+			start = min(start, b[j].start)
+		}
+		end, ai, bj := start, i, j
+		for grew := true; grew; {
+			grew = false
+			if ai < len(a) && a[ai].start <= end {
+				end, ai, grew = max(end, a[ai].end), ai+1, true
+			}
+			if bj < len(b) && b[bj].start <= end {
+				end, bj, grew = max(end, b[bj].end), bj+1, true
+			}
+		}
+
+		out.WriteString(strings.Join(baseLines[pos:start], ""))
+		oursText := applyHunks(baseLines, start, end, a[i:ai])
+		theirsText := applyHunks(baseLines, start, end, b[j:bj])
+		switch {
+		case ai == i:
+			out.WriteString(theirsText)
+		case bj == j, oursText == theirsText:
+			out.WriteString(oursText)
+		default:
+			conflict = true
+			switch strategy {
+			case ScaffoldMergeTheirs:
+				out.WriteString(theirsText)
+			case ScaffoldMergeMarkers:
+				out.WriteString("<<<<<<< repository\n" + withNewline(oursText) + "=======\n" + withNewline(theirsText) + ">>>>>>> template\n")
+			default:
+				out.WriteString(oursText)
+			}
+		}
+		pos, i, j = end, ai, bj
+	}
+	out.WriteString(strings.Join(baseLines[pos:], ""))
+	return out.String(), conflict
+}
+
+// lineHunks lists the line changes turning base into other, in order
+func lineHunks(base, other string) []mergeHunk {
+	var hunks []mergeHunk
+	i := 0
+	for _, d := range diff.Do(base, other) {
+		lines := splitLines(d.Text)
+		if d.Type == diffmatchpatch.DiffEqual {
+			i += len(lines)
+			continue
+		}
+		if n := len(hunks); n == 0 || hunks[n-1].end != i {
+			hunks = append(hunks, mergeHunk{start: i, end: i})
+		}
+		h := &hunks[len(hunks)-1]
+		if d.Type == diffmatchpatch.DiffDelete {
+			i += len(lines)
+			h.end = i
+		} else {
+			h.lines = append(h.lines, lines...)
+		}
+	}
+	return hunks
+}
+
+// applyHunks renders base lines [start, end) with hunks applied
+func applyHunks(base []string, start, end int, hunks []mergeHunk) string {
+	var out strings.Builder
+	k := start
+	for _, h := range hunks {
+		out.WriteString(strings.Join(base[k:h.start], ""))
+		out.WriteString(strings.Join(h.lines, ""))
+		k = h.end
+	}
+	out.WriteString(strings.Join(base[k:end], ""))
+	return out.String()
+}
+
+// splitLines splits s after each newline; the last line may lack one
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func withNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
+
+// nodesByPath indexes files by their slash-trimmed path
+func nodesByPath(files []FileNode) map[string]FileNode {
+	index := make(map[string]FileNode, len(files))
+	for _, f := range files {
+		index[strings.Trim(f.Path, "/")] = f
+	}
+	return index
+}
+
+// mergePaths returns the union of the paths of both indexes
+func mergePaths(a, b map[string]FileNode) map[string]bool {
+	paths := make(map[string]bool, len(a)+len(b))
+	for p := range a {
+		paths[p] = true
+	}
+	for p := range b {
+		paths[p] = true
+	}
+	return paths
+}
@@ -0,0 +1,355 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"iter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes reported to Metrics
+const (
+	OutcomeSuccess      = "success"
+	OutcomeNotFound     = "not_found"
+	OutcomeConflict     = "conflict"
+	OutcomeUnauthorized = "unauthorized"
+	OutcomeRateLimited  = "rate_limited"
+	OutcomeUnavailable  = "unavailable"
+	OutcomeNotModified  = "not_modified"
+	OutcomeLimit        = "limit_exceeded"
+	OutcomeInvalidPath  = "invalid_path"
+	OutcomeCanceled     = "canceled"
+	OutcomeError        = "error"
+)
+
+// Metrics receives one observation per Adapter call. bytes is the content size read or written, 0 when unknown.
+type Metrics interface {
+	ObserveOperation(operation, outcome string, duration time.Duration, bytes int)
+}
+
+// metricsAdapter wraps an Adapter, reporting every call to Metrics
+type metricsAdapter struct {
+	next    Adapter
+	metrics Metrics
+}
+
+var _ Adapter = (*metricsAdapter)(nil)
+
+// NewMetricsAdapter wraps next so every Adapter call is reported to metrics
+func NewMetricsAdapter(next Adapter, metrics Metrics) Adapter {
+	return &metricsAdapter{next: next, metrics: metrics}
+}
+
+func (m *metricsAdapter) GetFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	start := time.Now()
+	file, err := m.next.GetFile(ctx, projectID, path, opts...)
+	size := 0
+	if file != nil {
+		size = int(file.Size)
+	}
+	m.observe("GetFile", start, err, size)
+	return file, err
+}
+
+func (m *metricsAdapter) GetFiles(ctx context.Context, projectID uuid.UUID, paths []string, opts ...Option) (map[string]*FileNode, error) {
+	start := time.Now()
+	files, err := m.next.GetFiles(ctx, projectID, paths, opts...)
+	size := 0
+	for _, f := range files {
+		size += int(f.Size)
+	}
+	m.observe("GetFiles", start, err, size)
+	return files, err
+}
+
+func (m *metricsAdapter) StatFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	start := time.Now()
+	file, err := m.next.StatFile(ctx, projectID, path, opts...)
+	m.observe("StatFile", start, err, 0)
+	return file, err
+}
+
+func (m *metricsAdapter) ListFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	start := time.Now()
+	nodes, err := m.next.ListFiles(ctx, projectID, path, opts...)
+	m.observe("ListFiles", start, err, 0)
+	return nodes, err
+}
+
+func (m *metricsAdapter) ListFilesRecursive(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	start := time.Now()
+	nodes, err := m.next.ListFilesRecursive(ctx, projectID, path, opts...)
+	m.observe("ListFilesRecursive", start, err, 0)
+	return nodes, err
+}
+
+// IterateFiles observes the whole iteration, from the first entry until the caller stops or the listing ends
+func (m *metricsAdapter) IterateFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) iter.Seq2[FileNode, error] {
+	return func(yield func(FileNode, error) bool) {
+		start := time.Now()
+		var err error
+		for node, e := range m.next.IterateFiles(ctx, projectID, path, opts...) {
+			err = cmp.Or(err, e)
+			if !yield(node, e) {
+				break
+			}
+		}
+		m.observe("IterateFiles", start, err, 0)
+	}
+}
+
+func (m *metricsAdapter) CommitFile(ctx context.Context, projectID uuid.UUID, path, content, message string, opts ...Option) error {
+	start := time.Now()
+	err := m.next.CommitFile(ctx, projectID, path, content, message, opts...)
+	m.observe("CommitFile", start, err, len(content))
+	return err
+}
+
+func (m *metricsAdapter) OpenFile(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) (io.ReadCloser, error) {
+	start := time.Now()
+	r, err := m.next.OpenFile(ctx, projectID, path, ref, opts...)
+	m.observe("OpenFile", start, err, 0)
+	return r, err
+}
+
+func (m *metricsAdapter) CommitFileBytes(ctx context.Context, projectID uuid.UUID, path string, content []byte, message string, opts ...Option) error {
+	start := time.Now()
+	err := m.next.CommitFileBytes(ctx, projectID, path, content, message, opts...)
+	m.observe("CommitFileBytes", start, err, len(content))
+	return err
+}
+
+func (m *metricsAdapter) WriteFile(ctx context.Context, projectID uuid.UUID, path string, r io.Reader, message string, opts ...Option) error {
+	start := time.Now()
+	err := m.next.WriteFile(ctx, projectID, path, r, message, opts...)
+	m.observe("WriteFile", start, err, 0)
+	return err
+}
+
+func (m *metricsAdapter) CommitFiles(ctx context.Context, projectID uuid.UUID, files []FileChange, message string, opts ...Option) error {
+	start := time.Now()
+	err := m.next.CommitFiles(ctx, projectID, files, message, opts...)
+	size := 0
+	for _, f := range files {
+		size += len(f.data())
+	}
+	m.observe("CommitFiles", start, err, size)
+	return err
+}
+
+func (m *metricsAdapter) NewChangeSet(projectID uuid.UUID) *ChangeSet {
+	return newChangeSet(m, projectID)
+}
+
+func (m *metricsAdapter) PlanChanges(ctx context.Context, projectID uuid.UUID, changes []FileChange, opts ...Option) (*Plan, error) {
+	start := time.Now()
+	plan, err := m.next.PlanChanges(ctx, projectID, changes, opts...)
+	m.observe("PlanChanges", start, err, 0)
+	return plan, err
+}
+
+func (m *metricsAdapter) DeleteFile(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	start := time.Now()
+	err := m.next.DeleteFile(ctx, projectID, path, message, opts...)
+	m.observe("DeleteFile", start, err, 0)
+	return err
+}
+
+func (m *metricsAdapter) DeletePath(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	start := time.Now()
+	err := m.next.DeletePath(ctx, projectID, path, message, opts...)
+	m.observe("DeletePath", start, err, 0)
+	return err
+}
+
+func (m *metricsAdapter) MoveFile(ctx context.Context, projectID uuid.UUID, oldPath, newPath, message string, opts ...Option) error {
+	start := time.Now()
+	err := m.next.MoveFile(ctx, projectID, oldPath, newPath, message, opts...)
+	m.observe("MoveFile", start, err, 0)
+	return err
+}
+
+func (m *metricsAdapter) CopyFile(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, srcPath, dstPath, message string) error {
+	start := time.Now()
+	err := m.next.CopyFile(ctx, srcProjectID, dstProjectID, srcPath, dstPath, message)
+	m.observe("CopyFile", start, err, 0)
+	return err
+}
+
+func (m *metricsAdapter) CopyFiles(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, paths map[string]string, message string) error {
+	start := time.Now()
+	err := m.next.CopyFiles(ctx, srcProjectID, dstProjectID, paths, message)
+	m.observe("CopyFiles", start, err, 0)
+	return err
+}
+
+func (m *metricsAdapter) ImportArchive(ctx context.Context, projectID uuid.UUID, r io.Reader, message string, opts ...Option) error {
+	start := time.Now()
+	err := m.next.ImportArchive(ctx, projectID, r, message, opts...)
+	m.observe("ImportArchive", start, err, 0)
+	return err
+}
+
+func (m *metricsAdapter) CreateRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) (string, error) {
+	start := time.Now()
+	name, err := m.next.CreateRepository(ctx, projectID, opts...)
+	m.observe("CreateRepository", start, err, 0)
+	return name, err
+}
+
+func (m *metricsAdapter) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := m.next.Ping(ctx)
+	m.observe("Ping", start, err, 0)
+	return err
+}
+
+func (m *metricsAdapter) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	start := time.Now()
+	info, err := m.next.ServerInfo(ctx)
+	m.observe("ServerInfo", start, err, 0)
+	return info, err
+}
+
+func (m *metricsAdapter) RepositoryExists(ctx context.Context, projectID uuid.UUID, opts ...Option) (bool, error) {
+	start := time.Now()
+	exists, err := m.next.RepositoryExists(ctx, projectID, opts...)
+	m.observe("RepositoryExists", start, err, 0)
+	return exists, err
+}
+
+func (m *metricsAdapter) ScaffoldProjectFiles(ctx context.Context, projectID uuid.UUID, files []FileNode) (*ScaffoldResult, error) {
+	return m.ScaffoldProjectFilesWithOptions(ctx, projectID, files, ScaffoldOptions{})
+}
+
+func (m *metricsAdapter) ScaffoldProjectFilesWithOptions(ctx context.Context, projectID uuid.UUID, files []FileNode, opts ScaffoldOptions) (*ScaffoldResult, error) {
+	start := time.Now()
+	result, err := m.next.ScaffoldProjectFilesWithOptions(ctx, projectID, files, opts)
+	size := 0
+	for _, f := range files {
+		size += len(f.Data())
+	}
+	m.observe("ScaffoldProjectFiles", start, err, size)
+	return result, err
+}
+
+func (m *metricsAdapter) ScaffoldFromTemplates(ctx context.Context, projectID uuid.UUID, fsys fs.FS, data any) (*ScaffoldResult, error) {
+	start := time.Now()
+	result, err := m.next.ScaffoldFromTemplates(ctx, projectID, fsys, data)
+	m.observe("ScaffoldFromTemplates", start, err, 0)
+	return result, err
+}
+
+func (m *metricsAdapter) ScaffoldFromFS(ctx context.Context, projectID uuid.UUID, fsys fs.FS, root string) (*ScaffoldResult, error) {
+	start := time.Now()
+	result, err := m.next.ScaffoldFromFS(ctx, projectID, fsys, root)
+	m.observe("ScaffoldFromFS", start, err, 0)
+	return result, err
+}
+
+func (m *metricsAdapter) MergeScaffold(ctx context.Context, projectID uuid.UUID, baseFiles, newFiles []FileNode, strategy ScaffoldMergeStrategy) (*ScaffoldMergeResult, error) {
+	start := time.Now()
+	result, err := m.next.MergeScaffold(ctx, projectID, baseFiles, newFiles, strategy)
+	m.observe("MergeScaffold", start, err, 0)
+	return result, err
+}
+
+func (m *metricsAdapter) SearchFiles(ctx context.Context, projectID uuid.UUID, query string, opts ...Option) ([]SearchMatch, error) {
+	start := time.Now()
+	matches, err := m.next.SearchFiles(ctx, projectID, query, opts...)
+	m.observe("SearchFiles", start, err, 0)
+	return matches, err
+}
+
+func (m *metricsAdapter) observe(operation string, start time.Time, err error, bytes int) {
+	m.metrics.ObserveOperation(operation, outcome(err), time.Since(start), bytes)
+}
+
+// outcome classifies err into one of the Outcome constants
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrNotFound):
+		return OutcomeNotFound
+	case errors.Is(err, ErrConflict):
+		return OutcomeConflict
+	case errors.Is(err, ErrUnauthorized):
+		return OutcomeUnauthorized
+	case errors.Is(err, ErrRateLimited):
+		return OutcomeRateLimited
+	case errors.Is(err, ErrUnavailable):
+		return OutcomeUnavailable
+	case errors.Is(err, ErrNotModified):
+		return OutcomeNotModified
+	case errors.Is(err, ErrLimit):
+		return OutcomeLimit
+	case errors.Is(err, ErrInvalidPath):
+		return OutcomeInvalidPath
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return OutcomeCanceled
+	}
+	return OutcomeError
+}
+
+// PrometheusMetrics implements Metrics as a prometheus.Collector; register it with prometheus.MustRegister
+type PrometheusMetrics struct {
+	operations *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	payload    *prometheus.HistogramVec
+}
+
+var _ prometheus.Collector = (*PrometheusMetrics)(nil)
+
+// NewPrometheusMetrics creates the git_operations_total, git_operation_duration_seconds
+// and git_operation_payload_bytes series under namespace
+func NewPrometheusMetrics(namespace string) *PrometheusMetrics {
+	return &PrometheusMetrics{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "git",
+			Name:      "operations_total",
+			Help:      "Git adapter calls by operation and outcome.",
+		}, []string{"operation", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "git",
+			Name:      "operation_duration_seconds",
+			Help:      "Git adapter call latency.",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+		}, []string{"operation", "outcome"}),
+		payload: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "git",
+			Name:      "operation_payload_bytes",
+			Help:      "Content size read or written by git adapter calls.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		}, []string{"operation"}),
+	}
+}
+
+func (p *PrometheusMetrics) ObserveOperation(operation, outcome string, duration time.Duration, bytes int) {
+	p.operations.WithLabelValues(operation, outcome).Inc()
+	p.duration.WithLabelValues(operation, outcome).Observe(duration.Seconds())
+	if bytes > 0 {
+		p.payload.WithLabelValues(operation).Observe(float64(bytes))
+	}
+}
+
+func (p *PrometheusMetrics) Describe(ch chan<- *prometheus.Desc) {
+	p.operations.Describe(ch)
+	p.duration.Describe(ch)
+	p.payload.Describe(ch)
+}
+
+func (p *PrometheusMetrics) Collect(ch chan<- prometheus.Metric) {
+	p.operations.Collect(ch)
+	p.duration.Collect(ch)
+	p.payload.Collect(ch)
+}
@@ -0,0 +1,26 @@
+package git
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// gitMode converts a FileChange mode to go-git. Empty converts to 0, which buildTree
+// resolves to the mode of the file being replaced.
+func (m FileMode) gitMode() (filemode.FileMode, error) {
+	switch m {
+	case "":
+		return 0, nil
+	case FileModeRegular:
+		return filemode.Regular, nil
+	case FileModeExecutable:
+		return filemode.Executable, nil
+	}
+	return 0, fmt.Errorf("unsupported file mode '%s'", m)
+}
+
+// fileModeOf formats a tree entry mode the way git and the Gitea tree API print it
+func fileModeOf(m filemode.FileMode) FileMode {
+	return FileMode(fmt.Sprintf("%06o", uint32(m)))
+}
@@ -0,0 +1,93 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// apply rewrites the text content of path according to n; binary content is returned as is
+func (n Normalization) apply(path string, content []byte) ([]byte, error) {
+	if n == (Normalization{}) {
+		return content, nil
+	}
+	if n.UTF8 {
+		// UTF-16 is full of NUL bytes, so it must be recognized before the binary check
+		content = decodeUTF16(content)
+		content = bytes.TrimPrefix(content, bomUTF8)
+	}
+	if isBinary(string(content)) {
+		return content, nil
+	}
+	if n.UTF8 && !utf8.Valid(content) {
+		return nil, fmt.Errorf("file '%s' is not valid UTF-8", path)
+	}
+
+	newline := []byte("\n")
+	switch n.LineEnding {
+	case LineEndingLF:
+		content = bytes.ReplaceAll(content, []byte("\r\n"), newline)
+	case LineEndingCRLF:
+		newline = []byte("\r\n")
+		content = bytes.ReplaceAll(bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n")), []byte("\n"), newline)
+	case "":
+	default:
+		return nil, fmt.Errorf("unknown line ending '%s'", n.LineEnding)
+	}
+	if n.TrailingNewline && len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, newline...)
+	}
+	return content, nil
+}
+
+// applyChanges normalizes the content of every change that writes one, leaving files untouched
+func (n Normalization) applyChanges(files []FileChange) ([]FileChange, error) {
+	if n == (Normalization{}) {
+		return files, nil
+	}
+	out := make([]FileChange, len(files))
+	for i, f := range files {
+		if f.Operation != FileOperationDelete {
+			data, err := n.apply(f.Path, f.data())
+			if err != nil {
+				return nil, err
+			}
+			if f.Bytes != nil {
+				f.Bytes = data
+			} else {
+				f.Content = string(data)
+			}
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// decodeUTF16 transcodes content starting with a UTF-16 byte order mark to UTF-8
+func decodeUTF16(content []byte) []byte {
+	var order func([]byte) uint16
+	switch {
+	case bytes.HasPrefix(content, bomUTF16LE):
+		order = func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 }
+	case bytes.HasPrefix(content, bomUTF16BE):
+		order = func(b []byte) uint16 { return uint16(b[0])<<8 | uint16(b[1]) }
+	default:
+		return content
+	}
+	units := make([]uint16, 0, len(content)/2)
+	for i := 2; i+1 < len(content); i += 2 {
+		units = append(units, order(content[i:]))
+	}
+	out := make([]byte, 0, len(units))
+	for _, r := range utf16.Decode(units) {
+		out = utf8.AppendRune(out, r)
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oauth2ExpiryMargin refreshes access tokens this long before they expire, or halfway through
+// shorter lifetimes, so a token does not run out before the server checks it
+const oauth2ExpiryMargin = time.Minute
+
+// OAuth2Credentials obtains access tokens from cfg.TokenURL and refreshes them shortly before they
+// expire. Requests go through httpClient, nil for http.DefaultClient. The same token is used for
+// every owner; combine several with OwnerCredentials when organizations use different clients.
+func OAuth2Credentials(cfg OAuth2Config, httpClient *http.Client) CredentialProvider {
+	return &oauth2Credentials{
+		cfg:          cfg,
+		http:         cmp.Or(httpClient, http.DefaultClient),
+		refreshToken: cfg.RefreshToken,
+	}
+}
+
+// oauth2Credentials caches the current access token and the refresh token the server last issued
+type oauth2Credentials struct {
+	cfg  OAuth2Config
+	http *http.Client
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	refreshAt    time.Time // zero when the server did not say when the token expires
+}
+
+func (o *oauth2Credentials) Token(ctx context.Context, owner string) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.accessToken != "" && (o.refreshAt.IsZero() || time.Now().Before(o.refreshAt)) {
+		return o.accessToken, nil
+	}
+
+	form := url.Values{"client_id": {o.cfg.ClientID}, "client_secret": {o.cfg.ClientSecret}}
+	if o.refreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", o.refreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	if len(o.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.cfg.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to get OAuth2 token: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := o.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get OAuth2 token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusBadRequest {
+		// RFC 6749 reports invalid clients and grants as 400
+		resp.StatusCode = http.StatusUnauthorized
+	}
+	if err := responseError(resp); err != nil {
+		return "", fmt.Errorf("failed to get OAuth2 token: %w", err)
+	}
+
+	var token struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode OAuth2 token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("failed to get OAuth2 token: empty access token: %w", ErrUnauthorized)
+	}
+
+	o.accessToken, o.refreshAt = token.AccessToken, time.Time{}
+	if token.ExpiresIn > 0 {
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		o.refreshAt = time.Now().Add(lifetime - min(oauth2ExpiryMargin, lifetime/2))
+	}
+	// Gitea rotates refresh tokens: the one just used is no longer valid
+	if token.RefreshToken != "" && token.RefreshToken != o.refreshToken {
+		o.refreshToken = token.RefreshToken
+		if o.cfg.OnRefresh != nil {
+			o.cfg.OnRefresh(token.RefreshToken)
+		}
+	}
+	return o.accessToken, nil
+}
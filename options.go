@@ -0,0 +1,219 @@
+package git
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// defaultPageSize is the page size of WithPage without a limit and of full Gitea listings
+const defaultPageSize = 50
+
+// Option customizes a single adapter call
+type Option func(*callOptions)
+
+// callOptions is the resolved set of per-call settings
+type callOptions struct {
+	branch      string
+	owner       string // empty means the adapter's configured owner
+	expectedSHA string
+	idempotent  bool
+	progress    ProgressFunc
+	trailers    []Trailer
+	resolveLFS  bool
+	page        int // 1-based page selected by WithPage, 0 for every entry
+	limit       int
+	authorName  string // empty keeps the adapter identity
+	authorEmail string
+	onCommit    func(sha string) // set by the audit adapter
+	repo        CreateRepositoryOptions
+	ifNoneMatch string
+	format      CommitFormat // set by the adapter committing
+	commitType  string
+	commitScope string
+	jobID       string
+}
+
+// WithBranch runs the call against branch instead of the configured default.
+// Gitea reads also accept a tag or commit SHA here. An empty branch keeps the default.
+func WithBranch(branch string) Option {
+	return func(o *callOptions) {
+		if branch != "" {
+			o.branch = branch
+		}
+	}
+}
+
+// WithOwner runs the call against the repository of the same project under owner, a user or
+// organization, instead of the configured owner. The local and memory adapters keep a single
+// namespace per project ID and only reflect owner in the name CreateRepository returns.
+func WithOwner(owner string) Option {
+	return func(o *callOptions) {
+		if owner != "" {
+			o.owner = owner
+		}
+	}
+}
+
+// WithExpectedSHA makes CommitFile, CommitFileBytes and WriteFile fail with ErrConflict unless
+// the file still has blob SHA sha, e.g. the SHA returned by GetFile or StatFile. This turns a
+// read-modify-write cycle into a compare-and-swap; a deleted file also counts as changed.
+// SubmitReview and EnableAutoMerge take sha as the head commit of the pull request, so checks,
+// review and merge see the same code.
+func WithExpectedSHA(sha string) Option {
+	return func(o *callOptions) {
+		o.expectedSHA = sha
+	}
+}
+
+// WithIdempotent makes create calls succeed when the target already exists,
+// returning the existing resource instead of an error
+func WithIdempotent() Option {
+	return func(o *callOptions) {
+		o.idempotent = true
+	}
+}
+
+// WithProgress reports each file ImportArchive reads, then once more with Total set after
+// the commit has landed. Use ScaffoldOptions.Progress for scaffolding.
+func WithProgress(fn ProgressFunc) Option {
+	return func(o *callOptions) {
+		o.progress = fn
+	}
+}
+
+// WithTrailer appends a "key: value" trailer to the commit message, e.g. WithTrailer("Job-Id", id),
+// so every commit can be tied back to the job that made it. Read trailers back with ParseTrailers
+// or Commit.Trailers. Repeat the option to add several; the memory adapter keeps no messages.
+func WithTrailer(key, value string) Option {
+	return func(o *callOptions) {
+		o.trailers = append(o.trailers, Trailer{Key: key, Value: value})
+	}
+}
+
+// WithRepositoryOptions sets the description, default branch, topics and visibility of the
+// repository CreateRepository creates. The memory adapter keeps none of them.
+func WithRepositoryOptions(repo CreateRepositoryOptions) Option {
+	return func(o *callOptions) {
+		o.repo = repo
+	}
+}
+
+// withCommitHook reports the SHA of the commit a call makes to fn, where the backend returns it
+func withCommitHook(fn func(sha string)) Option {
+	return func(o *callOptions) {
+		o.onCommit = fn
+	}
+}
+
+// committed reports sha to the withCommitHook hook, if any
+func (o callOptions) committed(sha string) {
+	if o.onCommit != nil && sha != "" {
+		o.onCommit(sha)
+	}
+}
+
+// message returns the commit message shaped by the adapter's CommitFormat, with any WithTrailer,
+// WithCoAuthor and WithJobID trailers appended
+func (o callOptions) message(message string) string {
+	commitType, scope := o.format.Type, o.format.Scope
+	if o.commitType != "" {
+		commitType, scope = o.commitType, o.commitScope
+	}
+	trailers := slices.Clone(o.trailers)
+	if o.jobID != "" {
+		trailers = append(trailers, Trailer{Key: cmp.Or(o.format.JobTrailer, defaultJobTrailer), Value: o.jobID})
+	}
+	for _, author := range o.format.CoAuthors {
+		trailers = append(trailers, Trailer{Key: coAuthorTrailer, Value: author})
+	}
+	return appendTrailers(conventionalMessage(message, commitType, scope), trailers)
+}
+
+// WithCommitType prefixes the subject with a conventional commits type and optional scope, e.g.
+// WithCommitType("feat", "api") gives "feat(api): ...", overriding CommitFormat.Type and Scope
+func WithCommitType(commitType, scope string) Option {
+	return func(o *callOptions) {
+		o.commitType, o.commitScope = commitType, scope
+	}
+}
+
+// WithJobID ties the commit to the job that made it with a CommitFormat.JobTrailer trailer,
+// "Job-Id: <id>" by default. An empty id is ignored.
+func WithJobID(id string) Option {
+	return func(o *callOptions) {
+		o.jobID = id
+	}
+}
+
+// WithCoAuthor credits name <email> with a Co-authored-by trailer, on top of CommitFormat.CoAuthors.
+// Repeat the option to credit several.
+func WithCoAuthor(name, email string) Option {
+	return func(o *callOptions) {
+		o.trailers = append(o.trailers, Trailer{Key: coAuthorTrailer, Value: name + " <" + email + ">"})
+	}
+}
+
+// WithAuthor attributes the commit to name <email>, e.g. the user who triggered it, while the
+// adapter identity stays the committer. The memory adapter keeps no authors. An empty name is ignored.
+func WithAuthor(name, email string) Option {
+	return func(o *callOptions) {
+		if name != "" {
+			o.authorName, o.authorEmail = name, email
+		}
+	}
+}
+
+// WithIfNoneMatch makes GetFile, ListFiles and ListFilesRecursive fail with ErrNotModified, without
+// downloading anything, while the file or directory still has SHA sha. Pass FileNode.SHA from an
+// earlier GetFile, listing or StatFile; StatFile of "" reports the SHA of the root directory.
+func WithIfNoneMatch(sha string) Option {
+	return func(o *callOptions) {
+		o.ifNoneMatch = sha
+	}
+}
+
+// notModified fails with ErrNotModified when sha is the one passed to WithIfNoneMatch
+func (o callOptions) notModified(path, sha string) error {
+	if o.ifNoneMatch != "" && o.ifNoneMatch == sha {
+		return fmt.Errorf("'%s' is still at %s: %w", path, sha, ErrNotModified)
+	}
+	return nil
+}
+
+// WithResolveLFS makes Gitea GetFile return the content of Git LFS objects instead of their
+// pointer; FileNode.LFS still describes the pointer. Other adapters have no LFS store and ignore it.
+func WithResolveLFS() Option {
+	return func(o *callOptions) {
+		o.resolveLFS = true
+	}
+}
+
+// newCallOptions applies opts on top of the adapter defaults
+func newCallOptions(defaultBranch string, opts []Option) callOptions {
+	o := callOptions{branch: defaultBranch}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithPage makes a listing return only page (1-based) of limit entries instead of every entry.
+// Gitea list calls fetch just that page; file listings page their top-level entries.
+// A limit of 0 uses 50, and a page past the end yields an empty result.
+func WithPage(page, limit int) Option {
+	return func(o *callOptions) {
+		if page > 0 {
+			o.page, o.limit = page, cmp.Or(max(limit, 0), defaultPageSize)
+		}
+	}
+}
+
+// paginate returns the entries of items selected by WithPage, or all of them without it
+func paginate[T any](o callOptions, items []T) []T {
+	if o.page == 0 {
+		return items
+	}
+	start := min((o.page-1)*o.limit, len(items))
+	return items[start:min(start+o.limit, len(items))]
+}
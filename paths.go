@@ -0,0 +1,73 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxPathLength = 4096 // PATH_MAX on Linux
+	maxNameLength = 255  // NAME_MAX on Linux, per path segment
+)
+
+// validatePath rejects paths Gitea and git would fail on or that leave the repository: NUL bytes,
+// invalid UTF-8, absolute paths, ".." and empty segments, and names too long for a checkout.
+// A trailing slash is allowed, and "" and "." name the root.
+func validatePath(path string) error {
+	if path == "" || path == "." {
+		return nil
+	}
+	reason := ""
+	switch {
+	case strings.ContainsRune(path, 0):
+		reason = "contains a NUL byte"
+	case !utf8.ValidString(path):
+		reason = "is not valid UTF-8"
+	case strings.HasPrefix(path, "/"):
+		reason = "is absolute"
+	case len(path) > maxPathLength:
+		reason = fmt.Sprintf("is longer than %d bytes", maxPathLength)
+	default:
+		for _, segment := range strings.Split(strings.TrimSuffix(path, "/"), "/") {
+			switch {
+			case segment == "..":
+				reason = "escapes the repository"
+			case segment == "" || segment == ".":
+				reason = "has an empty segment"
+			case len(segment) > maxNameLength:
+				reason = fmt.Sprintf("has a name longer than %d bytes", maxNameLength)
+			}
+			if reason != "" {
+				break
+			}
+		}
+	}
+	if reason != "" {
+		if len(path) > 80 {
+			path = path[:80] + "..."
+		}
+		return fmt.Errorf("path %q %s: %w", path, reason, ErrInvalidPath)
+	}
+	return nil
+}
+
+// validatePaths validates every path, see validatePath
+func validatePaths(paths ...string) error {
+	for _, path := range paths {
+		if err := validatePath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateChanges validates the paths of every change, see validatePath
+func validateChanges(files []FileChange) error {
+	for _, f := range files {
+		if err := validatePaths(f.Path, f.FromPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
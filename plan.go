@@ -0,0 +1,100 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// planChanges compares changes with the branch without writing anything. Conflicts are the
+// cases where CommitFiles would fail or silently overwrite: a stale SHA, creating an existing
+// file, changing a missing one, or moving onto an existing path.
+func planChanges(ctx context.Context, a Adapter, logger *slog.Logger, projectID uuid.UUID, changes []FileChange, opts []Option) (*Plan, error) {
+	logger.Info("PlanChanges", "projectID", projectID, "files", len(changes))
+
+	if err := validateChanges(changes); err != nil {
+		return nil, err
+	}
+	existing, err := blobIndex(ctx, a, projectID, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository files: %w", err)
+	}
+
+	plan := &Plan{ProjectID: projectID, Branch: newCallOptions("", opts).branch}
+	for _, c := range changes {
+		planned := PlannedChange{Path: c.Path, FromPath: c.FromPath}
+		source, exists := existing[sourcePath(c)]
+		planned.SHA = source.SHA
+
+		operation, err := resolveOperation(c, exists)
+		switch {
+		case err != nil:
+			planned.Action, planned.Reason = PlanConflict, err.Error()
+		case c.SHA != "" && exists && c.SHA != source.SHA:
+			planned.Action = PlanConflict
+			planned.Reason = fmt.Sprintf("changed since it was read (expected %s, found %s)", c.SHA, source.SHA)
+		case operation == FileOperationDelete:
+			planned.Action = PlanDelete
+		case c.FromPath != "":
+			planned.Action = PlanUpdate
+			if dest, ok := existing[c.Path]; ok && c.Path != c.FromPath {
+				planned.Action, planned.SHA = PlanConflict, dest.SHA
+				planned.Reason = "destination already exists"
+			}
+		case operation == FileOperationCreate:
+			planned.Action = PlanCreate
+		case blobSHA(string(c.data())) == source.SHA && (c.Mode == "" || c.Mode == source.Mode):
+			planned.Action = PlanNoop
+		default:
+			planned.Action = PlanUpdate
+		}
+		plan.Changes = append(plan.Changes, planned)
+	}
+	return plan, nil
+}
+
+// Plan previews the staged changes with PlanChanges; move sources need not be read for it
+func (cs *ChangeSet) Plan(ctx context.Context, opts ...Option) (*Plan, error) {
+	if err := cs.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid change set: %w", err)
+	}
+	return cs.adapter.PlanChanges(ctx, cs.projectID, cs.Changes(), opts...)
+}
+
+// HasConflicts reports whether any change conflicts with the branch
+func (p *Plan) HasConflicts() bool {
+	return p.Count(PlanConflict) > 0
+}
+
+// Count returns the number of changes planned with action
+func (p *Plan) Count(action PlanAction) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// String renders the plan for operators, one change per line followed by a summary
+func (p *Plan) String() string {
+	symbols := map[PlanAction]string{PlanCreate: "+", PlanUpdate: "~", PlanDelete: "-", PlanConflict: "!", PlanNoop: "="}
+	var b strings.Builder
+	for _, c := range p.Changes {
+		fmt.Fprintf(&b, "  %s %s", symbols[c.Action], c.Path)
+		if c.FromPath != "" {
+			fmt.Fprintf(&b, " (from %s)", c.FromPath)
+		}
+		if c.Reason != "" {
+			fmt.Fprintf(&b, ": %s", c.Reason)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete, %d conflicts, %d unchanged.\n",
+		p.Count(PlanCreate), p.Count(PlanUpdate), p.Count(PlanDelete), p.Count(PlanConflict), p.Count(PlanNoop))
+	return b.String()
+}
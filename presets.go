@@ -0,0 +1,155 @@
+package git
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// presetMessage is the commit message of the files CreateRepositoryOptions.Presets adds
+const presetMessage = "Add .gitignore and .gitattributes"
+
+// readmeMessage is the commit message of a README rendered from CreateRepositoryOptions.Readme
+const readmeMessage = "Add README.md"
+
+// presetRules are the ignore and attribute lines of a Preset
+type presetRules struct {
+	ignore     []string
+	attributes []string
+}
+
+// commonRules are part of every rendering: OS and editor litter, local secrets and text normalization
+var commonRules = presetRules{
+	ignore:     []string{".DS_Store", "Thumbs.db", ".idea/", ".vscode/", "*.swp", "*~", ".env", ".env.*", "!.env.example"},
+	attributes: []string{"* text=auto eol=lf", "*.bat text eol=crlf", "*.cmd text eol=crlf", "*.ps1 text eol=crlf"},
+}
+
+var presets = map[Preset]presetRules{
+	PresetGo: {
+		ignore:     []string{"/bin/", "/dist/", "*.exe", "*.test", "*.out", "coverage.*", "go.work", "go.work.sum"},
+		attributes: []string{"go.sum linguist-generated=true", "*.pb.go linguist-generated=true"},
+	},
+	PresetNode: {
+		ignore:     []string{"node_modules/", "dist/", "build/", ".next/", ".nuxt/", "coverage/", "*.log", ".npm/", ".eslintcache", "*.tsbuildinfo"},
+		attributes: []string{"package-lock.json linguist-generated=true -diff", "yarn.lock linguist-generated=true -diff", "pnpm-lock.yaml linguist-generated=true -diff"},
+	},
+	PresetPython: {
+		ignore:     []string{"__pycache__/", "*.py[cod]", ".venv/", "venv/", "*.egg-info/", "build/", "dist/", ".pytest_cache/", ".mypy_cache/", ".coverage", "htmlcov/"},
+		attributes: []string{"*.py text diff=python", "*.ipynb -diff"},
+	},
+	PresetJava: {
+		ignore:     []string{"target/", "build/", ".gradle/", "out/", "*.class", "*.log", "hs_err_pid*"},
+		attributes: []string{"*.java text diff=java", "gradlew text eol=lf", "*.jar binary"},
+	},
+	PresetRust: {
+		ignore:     []string{"/target/", "**/*.rs.bk"},
+		attributes: []string{"*.rs text diff=rust", "Cargo.lock linguist-generated=true"},
+	},
+	PresetLFS: {
+		attributes: []string{
+			"*.png filter=lfs diff=lfs merge=lfs -text",
+			"*.jpg filter=lfs diff=lfs merge=lfs -text",
+			"*.jpeg filter=lfs diff=lfs merge=lfs -text",
+			"*.gif filter=lfs diff=lfs merge=lfs -text",
+			"*.webp filter=lfs diff=lfs merge=lfs -text",
+			"*.psd filter=lfs diff=lfs merge=lfs -text",
+			"*.mp4 filter=lfs diff=lfs merge=lfs -text",
+			"*.mov filter=lfs diff=lfs merge=lfs -text",
+			"*.mp3 filter=lfs diff=lfs merge=lfs -text",
+			"*.wav filter=lfs diff=lfs merge=lfs -text",
+			"*.zip filter=lfs diff=lfs merge=lfs -text",
+			"*.tar.gz filter=lfs diff=lfs merge=lfs -text",
+			"*.pdf filter=lfs diff=lfs merge=lfs -text",
+			"*.woff2 filter=lfs diff=lfs merge=lfs -text",
+		},
+	},
+}
+
+// GitIgnore renders a .gitignore with the common rules followed by a section per preset
+func GitIgnore(presetList ...Preset) (string, error) {
+	return renderPresets(presetList, func(r presetRules) []string { return r.ignore })
+}
+
+// GitAttributes renders a .gitattributes with the common rules followed by a section per preset;
+// PresetLFS tracks media and archives with Git LFS
+func GitAttributes(presetList ...Preset) (string, error) {
+	return renderPresets(presetList, func(r presetRules) []string { return r.attributes })
+}
+
+// PresetFiles returns .gitignore and .gitattributes for presetList, ready for ScaffoldProjectFiles
+func PresetFiles(presetList ...Preset) ([]FileNode, error) {
+	ignore, err := GitIgnore(presetList...)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := GitAttributes(presetList...)
+	if err != nil {
+		return nil, err
+	}
+	return []FileNode{
+		{Name: ".gitignore", Path: ".gitignore", Type: FileTypeFile, Content: &ignore},
+		{Name: ".gitattributes", Path: ".gitattributes", Type: FileTypeFile, Content: &attributes},
+	}, nil
+}
+
+// withPresetFiles adds the files of presetList to files, keeping any .gitignore or .gitattributes files already has
+func withPresetFiles(files []FileNode, presetList []Preset) ([]FileNode, error) {
+	generated, err := PresetFiles(presetList...)
+	if err != nil {
+		return nil, err
+	}
+	out := slices.Clone(files)
+	for _, g := range generated {
+		if !slices.ContainsFunc(files, func(f FileNode) bool { return f.Path == g.Path }) {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+// renderPresets joins the lines pick selects from the common rules and each preset, skipping
+// lines an earlier section already has and presets without any
+func renderPresets(presetList []Preset, pick func(presetRules) []string) (string, error) {
+	seen := map[string]bool{}
+	var b strings.Builder
+	section := func(title string, lines []string) {
+		var fresh []string
+		for _, line := range lines {
+			if !seen[line] {
+				seen[line] = true
+				fresh = append(fresh, line)
+			}
+		}
+		if len(fresh) == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "# %s\n%s\n", title, strings.Join(fresh, "\n"))
+	}
+
+	section("common", pick(commonRules))
+	for _, p := range presetList {
+		rules, ok := presets[p]
+		if !ok {
+			return "", fmt.Errorf("unknown preset '%s'", p)
+		}
+		section(string(p), pick(rules))
+	}
+	return b.String(), nil
+}
+
+// renderReadme executes the CreateRepositoryOptions.Readme template with data
+func renderReadme(text string, data ReadmeData) (string, error) {
+	tmpl, err := template.New("README.md").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse README template: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render README template: %w", err)
+	}
+	return b.String(), nil
+}
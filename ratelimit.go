@@ -0,0 +1,37 @@
+package git
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitTransport holds every request until the shared token bucket allows it
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func newRateLimitTransport(next http.RoundTripper, env *GitConfig) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if env.RateLimit <= 0 {
+		return next
+	}
+	burst := env.RateBurst
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimitTransport{
+		next:    next,
+		limiter: rate.NewLimiter(rate.Limit(env.RateLimit), burst),
+	}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
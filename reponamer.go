@@ -0,0 +1,87 @@
+package git
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// uuidLen is the length of a UUID in its canonical string form
+const uuidLen = 36
+
+// RepoNamer names the Gitea repository of a project. Names must end with the project ID so
+// repositories can be mapped back to projects, see ProjectIDFromRepoName.
+type RepoNamer interface {
+	RepoName(projectID uuid.UUID) string
+}
+
+// RepoNamerFunc adapts a function to RepoNamer
+type RepoNamerFunc func(projectID uuid.UUID) string
+
+// RepoName calls f
+func (f RepoNamerFunc) RepoName(projectID uuid.UUID) string {
+	return f(projectID)
+}
+
+// UUIDRepoNamer names repositories exactly the project ID; it is the default
+func UUIDRepoNamer() RepoNamer {
+	return RepoNamerFunc(uuid.UUID.String)
+}
+
+// PrefixRepoNamer names repositories prefix followed by the project ID, e.g. "proj-" gives
+// "proj-0b5c...". The prefix is used as given.
+func PrefixRepoNamer(prefix string) RepoNamer {
+	return RepoNamerFunc(func(projectID uuid.UUID) string {
+		return prefix + projectID.String()
+	})
+}
+
+// SlugRepoNamer names repositories after a human-readable title, e.g. the project name looked up
+// by title: "My Shop" gives "my-shop-0b5c...". Projects without a title fall back to the ID alone.
+func SlugRepoNamer(title func(projectID uuid.UUID) string) RepoNamer {
+	return RepoNamerFunc(func(projectID uuid.UUID) string {
+		slug := slugify(title(projectID))
+		if slug == "" {
+			return projectID.String()
+		}
+		return slug + "-" + projectID.String()
+	})
+}
+
+// ProjectIDFromRepoName parses the project ID a RepoNamer put at the end of a repository name
+func ProjectIDFromRepoName(name string) (uuid.UUID, error) {
+	if len(name) > uuidLen {
+		name = name[len(name)-uuidLen:]
+	}
+	return uuid.Parse(name)
+}
+
+// repoNameMax leaves room for the project ID within Gitea's 100 character limit on repository names
+const repoNameMax = 100 - uuidLen - 1
+
+// slugify lowercases s and joins its letters and digits with single dashes
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+		if b.Len() >= repoNameMax {
+			break
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// repoName is the name of projectID's repository under the configured RepoNamer
+func (g *GiteaAdapter) repoName(projectID uuid.UUID) string {
+	return g.namer.RepoName(projectID)
+}
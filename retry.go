@@ -0,0 +1,128 @@
+package git
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// retryTransport retries idempotent requests that failed with a 5xx, a 429 or a dropped connection,
+// waiting with exponential backoff and jitter between attempts. Other requests, e.g. the POST creating
+// a repository or pull request, are only retried when the connection was refused, since the server may
+// have applied them before failing.
+type retryTransport struct {
+	next       http.RoundTripper
+	logger     *slog.Logger
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+func newRetryTransport(next http.RoundTripper, env *GitConfig) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if env.RetryMax < 1 {
+		return next
+	}
+	return &retryTransport{
+		next:       next,
+		logger:     env.Logger,
+		attempts:   env.RetryMax + 1,
+		backoff:    env.RetryBackoff,
+		maxBackoff: env.RetryMaxBackoff,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A body that cannot be rewound can only be sent once
+	attempts := t.attempts
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			// A RoundTripper must not modify the caller's request
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(r)
+		if attempt >= attempts || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.delay(attempt, resp)
+		if resp != nil {
+			t.logger.Warn("Retrying request", "method", req.Method, "path", req.URL.Path, "status", resp.Status, "attempt", attempt, "attempts", attempts)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			t.logger.Warn("Retrying request", "method", req.Method, "path", req.URL.Path, "err", err, "attempt", attempt, "attempts", attempts)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// delay is the exponential backoff for attempt with up to 50% jitter, or the server's Retry-After when given
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	wait := t.backoff << (attempt - 1)
+	if wait <= 0 || (t.maxBackoff > 0 && wait > t.maxBackoff) {
+		wait = t.maxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// retryable reports whether a request outcome is worth another attempt
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if !idempotent(req) {
+		// Only a refused connection proves the request never reached the server
+		return errors.Is(err, syscall.ECONNREFUSED)
+	}
+	if err != nil {
+		return errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, syscall.ECONNREFUSED) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, io.EOF)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// idempotent reports whether sending req twice has the effect of sending it once: by method, or
+// marked with an Idempotency-Key header as net/http's Transport recognizes
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, key := req.Header["Idempotency-Key"]
+	_, xKey := req.Header["X-Idempotency-Key"]
+	return key || xKey
+}
@@ -0,0 +1,160 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// historyAdapter is an Adapter that can also read past revisions, which reverting needs
+type historyAdapter interface {
+	Adapter
+	GetFileAtRef(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) (*FileNode, error)
+	GetDiff(ctx context.Context, projectID uuid.UUID, base, head string, opts ...Option) (*Diff, error)
+}
+
+// revertCommit commits the inverse of the changes between parent and sha onto the branch.
+// Reads and the commit all go through opts, so they address the same repository.
+// It fails with ErrConflict when a later commit has touched one of the reverted files.
+func revertCommit(ctx context.Context, h historyAdapter, logger *slog.Logger, projectID uuid.UUID, sha, parent, message string, opts []Option) error {
+	diff, err := h.GetDiff(ctx, projectID, parent, sha, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to revert %s: %w", sha, err)
+	}
+	if len(diff.Files) == 0 {
+		logger.Info("Nothing to revert", "projectID", projectID, "sha", sha)
+		return nil
+	}
+
+	changes := make([]FileChange, 0, len(diff.Files))
+	for _, fd := range diff.Files {
+		current := ""
+		node, err := h.StatFile(ctx, projectID, fd.Path, opts...)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to revert %s: %w", sha, err)
+		}
+		if node != nil {
+			current = node.SHA
+		}
+		if current != fd.NewSHA {
+			return fmt.Errorf("failed to revert %s: '%s' changed since: %w", sha, fd.Path, ErrConflict)
+		}
+
+		if fd.Status == DiffStatusAdded {
+			changes = append(changes, FileChange{Operation: FileOperationDelete, Path: fd.Path, SHA: current})
+			continue
+		}
+		old, err := h.GetFileAtRef(ctx, projectID, fd.Path, parent, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to revert %s: %w", sha, err)
+		}
+		change := FileChange{Operation: FileOperationUpdate, Path: fd.Path, Bytes: old.Data(), SHA: current}
+		if fd.Status == DiffStatusDeleted {
+			change.Operation = FileOperationCreate
+		}
+		changes = append(changes, change)
+	}
+
+	message = cmp.Or(message, fmt.Sprintf("Revert %s", sha[:min(len(sha), 10)]))
+	return h.CommitFiles(ctx, projectID, changes, message, opts...)
+}
+
+// cherryPick commits the changes between parent and sha onto the branch selected by opts, reading
+// both from the repository opts address. Files the branch changed since are merged line by line;
+// overlapping edits fail the whole pick with ErrConflict.
+func cherryPick(ctx context.Context, h historyAdapter, logger *slog.Logger, projectID uuid.UUID, sha, parent, message string, opts []Option) error {
+	diff, err := h.GetDiff(ctx, projectID, parent, sha, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to cherry-pick %s: %w", sha, err)
+	}
+
+	var changes []FileChange
+	var conflicts []string
+	for _, fd := range diff.Files {
+		current, err := h.GetFile(ctx, projectID, fd.Path, opts...)
+		if errors.Is(err, ErrNotFound) {
+			current, err = nil, nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to cherry-pick %s: %w", sha, err)
+		}
+
+		switch {
+		case current != nil && current.SHA == fd.NewSHA, current == nil && fd.Status == DiffStatusDeleted:
+			// Already in the state the commit leaves it in
+		case fd.Status == DiffStatusDeleted:
+			if current.SHA != fd.OldSHA {
+				conflicts = append(conflicts, fd.Path)
+				continue
+			}
+			changes = append(changes, FileChange{Operation: FileOperationDelete, Path: fd.Path, SHA: current.SHA})
+		case current == nil:
+			if fd.Status != DiffStatusAdded {
+				conflicts = append(conflicts, fd.Path)
+				continue
+			}
+			theirs, err := h.GetFileAtRef(ctx, projectID, fd.Path, sha, opts...)
+			if err != nil {
+				return fmt.Errorf("failed to cherry-pick %s: %w", sha, err)
+			}
+			changes = append(changes, FileChange{Operation: FileOperationCreate, Path: fd.Path, Bytes: theirs.Data()})
+		default:
+			theirs, err := h.GetFileAtRef(ctx, projectID, fd.Path, sha, opts...)
+			if err != nil {
+				return fmt.Errorf("failed to cherry-pick %s: %w", sha, err)
+			}
+			if current.SHA == fd.OldSHA {
+				changes = append(changes, FileChange{Operation: FileOperationUpdate, Path: fd.Path, Bytes: theirs.Data(), SHA: current.SHA})
+				continue
+			}
+			// The branch changed the file too: merge both sets of edits against the parent version
+			base := ""
+			if fd.Status == DiffStatusModified {
+				old, err := h.GetFileAtRef(ctx, projectID, fd.Path, parent, opts...)
+				if err != nil {
+					return fmt.Errorf("failed to cherry-pick %s: %w", sha, err)
+				}
+				base = string(old.Data())
+			}
+			if current.IsBinary || theirs.IsBinary {
+				conflicts = append(conflicts, fd.Path)
+				continue
+			}
+			merged, conflict := mergeText(base, string(current.Data()), string(theirs.Data()), ScaffoldMergeReport)
+			if conflict {
+				conflicts = append(conflicts, fd.Path)
+				continue
+			}
+			if merged == string(current.Data()) {
+				// The branch already has these edits
+				continue
+			}
+			changes = append(changes, FileChange{Operation: FileOperationUpdate, Path: fd.Path, Bytes: []byte(merged), SHA: current.SHA})
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("failed to cherry-pick %s: %s changed on the branch: %w", sha, strings.Join(conflicts, ", "), ErrConflict)
+	}
+	if len(changes) == 0 {
+		logger.Info("Nothing to cherry-pick", "projectID", projectID, "sha", sha)
+		return nil
+	}
+
+	// Record the origin the way git cherry-pick -x does
+	message = fmt.Sprintf("%s\n\n(cherry picked from commit %s)", strings.TrimRight(message, "\n"), sha)
+	return h.CommitFiles(ctx, projectID, changes, message, opts...)
+}
+
+// restoreFile commits the content path had at ref onto the branch
+func restoreFile(ctx context.Context, h historyAdapter, projectID uuid.UUID, path, ref string, opts []Option) error {
+	node, err := h.GetFileAtRef(ctx, projectID, path, ref, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to restore '%s' from '%s': %w", path, ref, err)
+	}
+	return h.CommitFileBytes(ctx, projectID, path, node.Data(), fmt.Sprintf("Restore %s from %s", path, ref), opts...)
+}
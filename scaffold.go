@@ -0,0 +1,294 @@
+package git
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// templateSuffix marks the files ScaffoldFromTemplates renders
+const templateSuffix = ".tmpl"
+
+// scaffold commits files through a pool of opts.Workers goroutines, recording the outcome of every path.
+// Files whose content already matches the branch are skipped without a commit.
+// The returned error joins all per-path failures so callers can retry ScaffoldResult.Failed.
+// norm is the Normalization a's commits apply, so unchanged files are recognized by what would be written.
+func scaffold(ctx context.Context, a Adapter, logger *slog.Logger, projectID uuid.UUID, files []FileNode, opts ScaffoldOptions, norm Normalization) (*ScaffoldResult, error) {
+	if len(opts.Presets) > 0 {
+		var err error
+		if files, err = withPresetFiles(files, opts.Presets); err != nil {
+			return nil, err
+		}
+	}
+	workers := max(opts.Workers, 1)
+	logger.Info("Starting scaffold", "projectID", projectID, "files", len(files), "workers", workers)
+
+	existing, err := blobIndex(ctx, a, projectID)
+	if err != nil {
+		// Without the index every file is committed, which is still correct
+		logger.Warn("Failed to list existing files, committing all", "projectID", projectID, "err", err)
+	}
+	var done map[string]string
+	if opts.Checkpoint != nil {
+		if done, err = opts.Checkpoint.Load(ctx, projectID); err != nil {
+			logger.Warn("Failed to load scaffold checkpoint, starting over", "projectID", projectID, "err", err)
+		} else if len(done) > 0 {
+			logger.Info("Resuming scaffold from checkpoint", "projectID", projectID, "done", len(done))
+		}
+	}
+
+	skipped := make([]bool, len(files))
+	shas := make([]string, len(files))
+	for i, file := range files {
+		data, err := norm.apply(file.Path, file.Data())
+		if err != nil {
+			// Leave it to the commit to report the error
+			shas[i] = blobSHA(string(file.Data()))
+			continue
+		}
+		shas[i] = blobSHA(string(data))
+		if n, ok := existing[file.Path]; ok && n.SHA == shas[i] && (file.Mode == "" || n.Mode == file.Mode) {
+			skipped[i] = true
+		} else if sha, ok := done[file.Path]; ok && sha == shas[i] {
+			skipped[i] = true
+		}
+	}
+
+	// A shared ticker spaces out requests across all workers
+	var throttle <-chan time.Time
+	if opts.Interval > 0 {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	var (
+		progressMu sync.Mutex
+		progress   = Progress{Total: len(files)}
+	)
+	report := func(file FileNode) {
+		if opts.Progress == nil {
+			return
+		}
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		progress.Done++
+		progress.Path = file.Path
+		progress.Bytes += int64(len(file.Data()))
+		opts.Progress(progress)
+	}
+
+	errs := make([]error, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if skipped[i] {
+					logger.Debug("Skipping unchanged scaffold file", "path", files[i].Path)
+				} else {
+					logger.Debug("Committing scaffold file", "n", i+1, "total", len(files), "path", files[i].Path)
+					errs[i] = scaffoldFile(ctx, a, logger, projectID, files[i], opts, throttle)
+					if errs[i] == nil && opts.Checkpoint != nil {
+						if err := opts.Checkpoint.Save(ctx, projectID, files[i].Path, shas[i]); err != nil {
+							logger.Warn("Failed to save scaffold checkpoint", "projectID", projectID, "path", files[i].Path, "err", err)
+						}
+					}
+				}
+				report(files[i])
+			}
+		}()
+	}
+
+dispatch:
+	for i := range files {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			for j := i; j < len(files); j++ {
+				errs[j] = ctx.Err()
+			}
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := &ScaffoldResult{Errors: map[string]error{}}
+	for i, file := range files {
+		if errs[i] != nil {
+			logger.Error("Scaffold file failed", "projectID", projectID, "path", file.Path, "err", errs[i])
+			result.Failed = append(result.Failed, file.Path)
+			result.Errors[file.Path] = errs[i]
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, file.Path)
+		switch _, found := existing[file.Path]; {
+		case skipped[i]:
+			result.Skipped = append(result.Skipped, file.Path)
+		case found:
+			result.Updated = append(result.Updated, file.Path)
+		case existing != nil:
+			result.Created = append(result.Created, file.Path)
+		}
+	}
+
+	if opts.Checkpoint != nil && len(result.Failed) == 0 {
+		if err := opts.Checkpoint.Clear(ctx, projectID); err != nil {
+			logger.Warn("Failed to clear scaffold checkpoint", "projectID", projectID, "err", err)
+		}
+	}
+
+	logger.Info("Scaffold completed", "projectID", projectID, "created", len(result.Created), "updated", len(result.Updated),
+		"skipped", len(result.Skipped), "failed", len(result.Failed))
+	return result, result.Err()
+}
+
+// blobIndex maps every file on the branch to its listing node, for the blob SHA and mode.
+// A repository or branch that does not exist yet yields an empty index.
+func blobIndex(ctx context.Context, a Adapter, projectID uuid.UUID, opts ...Option) (map[string]FileNode, error) {
+	nodes, err := a.ListFilesRecursive(ctx, projectID, "", opts...)
+	if errors.Is(err, ErrNotFound) {
+		return map[string]FileNode{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]FileNode{}
+	for _, n := range flattenFiles(nodes) {
+		index[n.Path] = n
+	}
+	return index, nil
+}
+
+// scaffoldFile commits a single file, retrying up to opts.Retries times with exponential backoff.
+// Files with a Mode go through CommitFiles, the only call that sets one.
+func scaffoldFile(ctx context.Context, a Adapter, logger *slog.Logger, projectID uuid.UUID, file FileNode, opts ScaffoldOptions, throttle <-chan time.Time) error {
+	if file.Content == nil && file.Bytes == nil {
+		return errors.New("missing file content")
+	}
+
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+	msg := fmt.Sprintf("Scaffold path: %s", file.Path)
+
+	var err error
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			logger.Warn("Retrying scaffold file", "path", file.Path, "attempt", attempt+1, "attempts", opts.Retries+1, "err", err)
+			select {
+			case <-time.After(delay):
+				delay *= 2
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if throttle != nil {
+			select {
+			case <-throttle:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if file.Mode != "" {
+			err = a.CommitFiles(ctx, projectID, []FileChange{{Path: file.Path, Bytes: file.Data(), Mode: file.Mode}}, msg)
+		} else {
+			err = a.CommitFile(ctx, projectID, file.Path, string(file.Data()), msg)
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// renderTemplates reads every file in fsys, executing those named *.tmpl as text/template with data
+// and dropping the suffix. Other files are copied verbatim so binary assets can sit next to templates.
+func renderTemplates(fsys fs.FS, data any) ([]FileNode, error) {
+	return readFS(fsys, func(name string, raw []byte) (string, []byte, error) {
+		target, isTemplate := strings.CutSuffix(name, templateSuffix)
+		if !isTemplate {
+			return name, raw, nil
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(string(raw))
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to parse template '%s': %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", nil, fmt.Errorf("failed to render template '%s': %w", name, err)
+		}
+		return target, buf.Bytes(), nil
+	})
+}
+
+// subFiles reads the tree below root in fsys; an empty root reads the whole FS
+func subFiles(fsys fs.FS, root string) ([]FileNode, error) {
+	sub, err := fs.Sub(fsys, cmp.Or(root, "."))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s': %w", root, err)
+	}
+	return readFS(sub, nil)
+}
+
+// readFS turns every regular file in fsys into a FileNode keyed by its slash-separated path,
+// marking files with any execute bit as FileModeExecutable.
+// A non-nil transform may rewrite the path and content of each file.
+func readFS(fsys fs.FS, transform func(name string, raw []byte) (string, []byte, error)) ([]FileNode, error) {
+	var files []FileNode
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("failed to read '%s': unsupported file type %s", name, d.Type())
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to read '%s': %w", name, err)
+		}
+		raw, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("failed to read '%s': %w", name, err)
+		}
+		if transform != nil {
+			if name, raw, err = transform(name, raw); err != nil {
+				return err
+			}
+		}
+
+		file := FileNode{Name: path.Base(name), Path: name, Type: FileTypeFile}
+		if info.Mode()&0o111 != 0 {
+			file.Mode = FileModeExecutable
+		}
+		file.setContent(raw)
+		files = append(files, file)
+		return nil
+	})
+	return files, err
+}
+
+// Err joins the per-path errors in file order, or returns nil when every path succeeded
+func (r *ScaffoldResult) Err() error {
+	var errs []error
+	for _, path := range r.Failed {
+		errs = append(errs, fmt.Errorf("scaffold path %s: %w", path, r.Errors[path]))
+	}
+	return errors.Join(errs...)
+}
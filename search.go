@@ -0,0 +1,68 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxSearchFileSize skips large files, which are rarely config and expensive to fetch
+	maxSearchFileSize = 1 << 20
+
+	// searchBatchSize bounds how many files are held in memory at once
+	searchBatchSize = 100
+)
+
+// searchFiles greps every text file on the branch for query, a case-sensitive substring.
+// The Gitea API has no code search endpoint, so all backends walk the tree.
+func searchFiles(ctx context.Context, a Adapter, logger *slog.Logger, projectID uuid.UUID, query string, opts []Option) ([]SearchMatch, error) {
+	logger.Info("SearchFiles", "projectID", projectID, "query", query)
+	if query == "" {
+		return nil, errors.New("failed to search files: empty query")
+	}
+
+	nodes, err := a.ListFilesRecursive(ctx, projectID, "", opts...)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, n := range flattenFiles(nodes) {
+		if n.Type == FileTypeFile && n.Size <= maxSearchFileSize {
+			paths = append(paths, n.Path)
+		}
+	}
+
+	var matches []SearchMatch
+	for batch := range slices.Chunk(paths, searchBatchSize) {
+		files, err := a.GetFiles(ctx, projectID, batch, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search files: %w", err)
+		}
+		for _, p := range batch {
+			if f := files[p]; f != nil && !f.IsBinary && f.Content != nil {
+				matches = append(matches, grep(p, *f.Content, query)...)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// grep returns the lines of content containing query
+func grep(path, content, query string) []SearchMatch {
+	var matches []SearchMatch
+	sc := bufio.NewScanner(strings.NewReader(content))
+	sc.Buffer(nil, maxSearchFileSize)
+	for line := 1; sc.Scan(); line++ {
+		if strings.Contains(sc.Text(), query) {
+			matches = append(matches, SearchMatch{Path: path, Line: line, Text: sc.Text()})
+		}
+	}
+	return matches
+}
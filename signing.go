@@ -0,0 +1,123 @@
+package git
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	gogit "github.com/go-git/go-git/v5"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSigNamespace is the namespace git uses for SSH commit signatures
+const sshSigNamespace = "git"
+
+// loadSigner reads the private key at path, either an armored OpenPGP key or an OpenSSH key,
+// decrypting it with passphrase when it is protected. An empty path disables signing.
+func loadSigner(path, passphrase string) (gogit.Signer, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	if bytes.Contains(data, []byte("BEGIN PGP PRIVATE KEY BLOCK")) {
+		keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key: %w", err)
+		}
+		entity := keys[0]
+		if entity.PrivateKey == nil {
+			return nil, fmt.Errorf("failed to parse signing key: no private key in %s", path)
+		}
+		if entity.PrivateKey.Encrypted {
+			if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("failed to decrypt signing key: %w", err)
+			}
+		}
+		return pgpSigner{entity: entity}, nil
+	}
+
+	key, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		key, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	return sshSigner{key: key}, nil
+}
+
+// pgpSigner produces the armored detached signatures `git commit -S` writes with gpg
+type pgpSigner struct {
+	entity *openpgp.Entity
+}
+
+func (p pgpSigner) Sign(message io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, p.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("failed to sign commit: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sshSigner produces the SSHSIG signatures `git commit -S` writes when gpg.format is ssh
+type sshSigner struct {
+	key ssh.Signer
+}
+
+func (s sshSigner) Sign(message io.Reader) ([]byte, error) {
+	h := sha512.New()
+	if _, err := io.Copy(h, message); err != nil {
+		return nil, fmt.Errorf("failed to sign commit: %w", err)
+	}
+
+	// The signed blob and the signature share the namespace, reserved and hash fields
+	fields := sshString(nil, []byte(sshSigNamespace))
+	fields = sshString(fields, nil)
+	fields = sshString(fields, []byte("sha512"))
+	signed := sshString(append([]byte("SSHSIG"), fields...), h.Sum(nil))
+
+	var sig *ssh.Signature
+	var err error
+	if as, ok := s.key.(ssh.AlgorithmSigner); ok && s.key.PublicKey().Type() == ssh.KeyAlgoRSA {
+		// ssh-rsa (SHA-1) signatures are rejected by ssh-keygen -Y verify
+		sig, err = as.SignWithAlgorithm(rand.Reader, signed, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = s.key.Sign(rand.Reader, signed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign commit: %w", err)
+	}
+
+	blob := binary.BigEndian.AppendUint32([]byte("SSHSIG"), 1)
+	blob = sshString(blob, s.key.PublicKey().Marshal())
+	blob = append(blob, fields...)
+	blob = sshString(blob, ssh.Marshal(sig))
+
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var out strings.Builder
+	out.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 70 {
+		out.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	out.WriteString(encoded + "\n-----END SSH SIGNATURE-----\n")
+	return []byte(out.String()), nil
+}
+
+// sshString appends b to dst as an SSH wire string: a big-endian length followed by the bytes
+func sshString(dst, b []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(dst, uint32(len(b))), b...)
+}
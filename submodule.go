@@ -0,0 +1,115 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// gitmodulesPath is where git records submodule URLs
+const gitmodulesPath = ".gitmodules"
+
+// treeEdit computes the changes to apply on top of root, the tree at the branch tip (nil for
+// an unborn branch), writing any new blobs to s
+type treeEdit func(s storer.EncodedObjectStorer, root *object.Tree) (map[string]*localChange, error)
+
+// parseGitmodules maps submodule paths to their URLs
+func parseGitmodules(data []byte) (map[string]string, error) {
+	modules := config.NewModules()
+	if err := modules.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", gitmodulesPath, err)
+	}
+	urls := make(map[string]string, len(modules.Submodules))
+	for _, m := range modules.Submodules {
+		urls[m.Path] = m.URL
+	}
+	return urls, nil
+}
+
+// treeSubmodules reads the submodule URLs recorded in root; nil when there are none
+func treeSubmodules(root *object.Tree) map[string]string {
+	if root == nil {
+		return nil
+	}
+	file, err := root.File(gitmodulesPath)
+	if err != nil {
+		return nil
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil
+	}
+	urls, _ := parseGitmodules([]byte(content))
+	return urls
+}
+
+// addSubmodule returns an edit adding a gitlink at path pinned to sha, registered in .gitmodules
+func addSubmodule(path, url, sha string) treeEdit {
+	return func(s storer.EncodedObjectStorer, root *object.Tree) (map[string]*localChange, error) {
+		path = strings.Trim(path, "/")
+		if !plumbing.IsHash(sha) {
+			return nil, fmt.Errorf("failed to add submodule '%s': invalid commit SHA '%s'", path, sha)
+		}
+
+		modules := config.NewModules()
+		if root != nil {
+			if _, err := root.FindEntry(path); err == nil {
+				return nil, fmt.Errorf("failed to add submodule '%s': %w", path, ErrConflict)
+			}
+			if file, err := root.File(gitmodulesPath); err == nil {
+				content, err := file.Contents()
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", gitmodulesPath, err)
+				}
+				if err := modules.Unmarshal([]byte(content)); err != nil {
+					return nil, fmt.Errorf("failed to parse %s: %w", gitmodulesPath, err)
+				}
+			}
+		}
+
+		module := &config.Submodule{Name: path, Path: path, URL: url}
+		if err := module.Validate(); err != nil {
+			return nil, fmt.Errorf("failed to add submodule '%s': %w", path, err)
+		}
+		modules.Submodules[path] = module
+		data, err := modules.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", gitmodulesPath, err)
+		}
+		blob, err := storeBlob(s, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]*localChange{
+			gitmodulesPath: {Hash: blob, Mode: filemode.Regular},
+			path:           {Hash: plumbing.NewHash(sha), Mode: filemode.Submodule},
+		}, nil
+	}
+}
+
+// updateSubmodule returns an edit pointing the existing gitlink at path to sha
+func updateSubmodule(path, sha string) treeEdit {
+	return func(s storer.EncodedObjectStorer, root *object.Tree) (map[string]*localChange, error) {
+		path = strings.Trim(path, "/")
+		if !plumbing.IsHash(sha) {
+			return nil, fmt.Errorf("failed to update submodule '%s': invalid commit SHA '%s'", path, sha)
+		}
+		if root == nil {
+			return nil, fmt.Errorf("failed to update submodule '%s': %w", path, ErrNotFound)
+		}
+		entry, err := root.FindEntry(path)
+		if err != nil || entry.Mode != filemode.Submodule {
+			return nil, fmt.Errorf("failed to update submodule '%s': %w", path, ErrNotFound)
+		}
+		return map[string]*localChange{
+			path: {Hash: plumbing.NewHash(sha), Mode: filemode.Submodule},
+		}, nil
+	}
+}
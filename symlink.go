@@ -0,0 +1,33 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// createSymlink returns an edit adding a symlink at path whose blob holds target
+func createSymlink(path, target string) treeEdit {
+	return func(s storer.EncodedObjectStorer, root *object.Tree) (map[string]*localChange, error) {
+		path = strings.Trim(path, "/")
+		if path == "" || target == "" {
+			return nil, fmt.Errorf("failed to create symlink '%s': path and target are required", path)
+		}
+		if root != nil {
+			if _, err := root.FindEntry(path); err == nil {
+				return nil, fmt.Errorf("failed to create symlink '%s': %w", path, ErrConflict)
+			}
+		}
+
+		blob, err := storeBlob(s, strings.NewReader(target))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]*localChange{
+			path: {Hash: blob, Mode: filemode.Symlink},
+		}, nil
+	}
+}
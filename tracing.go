@@ -0,0 +1,285 @@
+package git
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"io/fs"
+	"iter"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/xehrad/git"
+
+// tracingAdapter wraps an Adapter, recording one span per call
+type tracingAdapter struct {
+	next   Adapter
+	tracer trace.Tracer
+}
+
+var _ Adapter = (*tracingAdapter)(nil)
+
+// NewTracingAdapter wraps next so every Adapter call is recorded as an OpenTelemetry span
+// carrying the project, path and outcome. A nil provider uses the global one.
+func NewTracingAdapter(next Adapter, provider trace.TracerProvider) Adapter {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &tracingAdapter{next: next, tracer: provider.Tracer(tracerName)}
+}
+
+func (t *tracingAdapter) GetFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	ctx, span := t.start(ctx, "GetFile", projectID, opts, attribute.String("git.path", path))
+	file, err := t.next.GetFile(ctx, projectID, path, opts...)
+	endSpan(span, err)
+	return file, err
+}
+
+func (t *tracingAdapter) GetFiles(ctx context.Context, projectID uuid.UUID, paths []string, opts ...Option) (map[string]*FileNode, error) {
+	ctx, span := t.start(ctx, "GetFiles", projectID, opts, attribute.Int("git.files", len(paths)))
+	files, err := t.next.GetFiles(ctx, projectID, paths, opts...)
+	endSpan(span, err)
+	return files, err
+}
+
+func (t *tracingAdapter) StatFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error) {
+	ctx, span := t.start(ctx, "StatFile", projectID, opts, attribute.String("git.path", path))
+	file, err := t.next.StatFile(ctx, projectID, path, opts...)
+	endSpan(span, err)
+	return file, err
+}
+
+func (t *tracingAdapter) ListFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	ctx, span := t.start(ctx, "ListFiles", projectID, opts, attribute.String("git.path", path))
+	nodes, err := t.next.ListFiles(ctx, projectID, path, opts...)
+	endSpan(span, err)
+	return nodes, err
+}
+
+func (t *tracingAdapter) ListFilesRecursive(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error) {
+	ctx, span := t.start(ctx, "ListFilesRecursive", projectID, opts, attribute.String("git.path", path))
+	nodes, err := t.next.ListFilesRecursive(ctx, projectID, path, opts...)
+	endSpan(span, err)
+	return nodes, err
+}
+
+// IterateFiles spans the whole iteration, from the first entry until the caller stops or the listing ends
+func (t *tracingAdapter) IterateFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) iter.Seq2[FileNode, error] {
+	return func(yield func(FileNode, error) bool) {
+		ctx, span := t.start(ctx, "IterateFiles", projectID, opts, attribute.String("git.path", path))
+		var err error
+		for node, e := range t.next.IterateFiles(ctx, projectID, path, opts...) {
+			err = cmp.Or(err, e)
+			if !yield(node, e) {
+				break
+			}
+		}
+		endSpan(span, err)
+	}
+}
+
+func (t *tracingAdapter) CommitFile(ctx context.Context, projectID uuid.UUID, path, content, message string, opts ...Option) error {
+	ctx, span := t.start(ctx, "CommitFile", projectID, opts,
+		attribute.String("git.path", path), attribute.Int("git.size", len(content)))
+	err := t.next.CommitFile(ctx, projectID, path, content, message, opts...)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) OpenFile(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) (io.ReadCloser, error) {
+	ctx, span := t.start(ctx, "OpenFile", projectID, nil, attribute.String("git.path", path), attribute.String("git.ref", ref))
+	r, err := t.next.OpenFile(ctx, projectID, path, ref, opts...)
+	endSpan(span, err)
+	return r, err
+}
+
+func (t *tracingAdapter) CommitFileBytes(ctx context.Context, projectID uuid.UUID, path string, content []byte, message string, opts ...Option) error {
+	ctx, span := t.start(ctx, "CommitFileBytes", projectID, opts,
+		attribute.String("git.path", path), attribute.Int("git.size", len(content)))
+	err := t.next.CommitFileBytes(ctx, projectID, path, content, message, opts...)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) WriteFile(ctx context.Context, projectID uuid.UUID, path string, r io.Reader, message string, opts ...Option) error {
+	ctx, span := t.start(ctx, "WriteFile", projectID, opts, attribute.String("git.path", path))
+	err := t.next.WriteFile(ctx, projectID, path, r, message, opts...)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) CommitFiles(ctx context.Context, projectID uuid.UUID, files []FileChange, message string, opts ...Option) error {
+	ctx, span := t.start(ctx, "CommitFiles", projectID, opts, attribute.Int("git.files", len(files)))
+	err := t.next.CommitFiles(ctx, projectID, files, message, opts...)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) NewChangeSet(projectID uuid.UUID) *ChangeSet {
+	return newChangeSet(t, projectID)
+}
+
+func (t *tracingAdapter) PlanChanges(ctx context.Context, projectID uuid.UUID, changes []FileChange, opts ...Option) (*Plan, error) {
+	ctx, span := t.start(ctx, "PlanChanges", projectID, opts, attribute.Int("git.files", len(changes)))
+	plan, err := t.next.PlanChanges(ctx, projectID, changes, opts...)
+	endSpan(span, err)
+	return plan, err
+}
+
+func (t *tracingAdapter) DeleteFile(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	ctx, span := t.start(ctx, "DeleteFile", projectID, opts, attribute.String("git.path", path))
+	err := t.next.DeleteFile(ctx, projectID, path, message, opts...)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) DeletePath(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error {
+	ctx, span := t.start(ctx, "DeletePath", projectID, opts, attribute.String("git.path", path))
+	err := t.next.DeletePath(ctx, projectID, path, message, opts...)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) MoveFile(ctx context.Context, projectID uuid.UUID, oldPath, newPath, message string, opts ...Option) error {
+	ctx, span := t.start(ctx, "MoveFile", projectID, opts,
+		attribute.String("git.path", newPath), attribute.String("git.from_path", oldPath))
+	err := t.next.MoveFile(ctx, projectID, oldPath, newPath, message, opts...)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) CopyFile(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, srcPath, dstPath, message string) error {
+	ctx, span := t.start(ctx, "CopyFile", dstProjectID, nil,
+		attribute.String("git.path", dstPath), attribute.String("git.from_path", srcPath),
+		attribute.String("git.from_project_id", srcProjectID.String()))
+	err := t.next.CopyFile(ctx, srcProjectID, dstProjectID, srcPath, dstPath, message)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) CopyFiles(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, paths map[string]string, message string) error {
+	ctx, span := t.start(ctx, "CopyFiles", dstProjectID, nil,
+		attribute.Int("git.files", len(paths)), attribute.String("git.from_project_id", srcProjectID.String()))
+	err := t.next.CopyFiles(ctx, srcProjectID, dstProjectID, paths, message)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) ImportArchive(ctx context.Context, projectID uuid.UUID, r io.Reader, message string, opts ...Option) error {
+	ctx, span := t.start(ctx, "ImportArchive", projectID, opts)
+	err := t.next.ImportArchive(ctx, projectID, r, message, opts...)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) CreateRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) (string, error) {
+	ctx, span := t.start(ctx, "CreateRepository", projectID, nil)
+	name, err := t.next.CreateRepository(ctx, projectID, opts...)
+	endSpan(span, err)
+	return name, err
+}
+
+func (t *tracingAdapter) Ping(ctx context.Context) error {
+	ctx, span := t.start(ctx, "Ping", uuid.Nil, nil)
+	err := t.next.Ping(ctx)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingAdapter) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	ctx, span := t.start(ctx, "ServerInfo", uuid.Nil, nil)
+	info, err := t.next.ServerInfo(ctx)
+	endSpan(span, err)
+	return info, err
+}
+
+func (t *tracingAdapter) RepositoryExists(ctx context.Context, projectID uuid.UUID, opts ...Option) (bool, error) {
+	ctx, span := t.start(ctx, "RepositoryExists", projectID, nil)
+	exists, err := t.next.RepositoryExists(ctx, projectID, opts...)
+	endSpan(span, err)
+	return exists, err
+}
+
+func (t *tracingAdapter) ScaffoldProjectFiles(ctx context.Context, projectID uuid.UUID, files []FileNode) (*ScaffoldResult, error) {
+	return t.ScaffoldProjectFilesWithOptions(ctx, projectID, files, ScaffoldOptions{})
+}
+
+func (t *tracingAdapter) ScaffoldProjectFilesWithOptions(ctx context.Context, projectID uuid.UUID, files []FileNode, opts ScaffoldOptions) (*ScaffoldResult, error) {
+	ctx, span := t.start(ctx, "ScaffoldProjectFiles", projectID, nil, attribute.Int("git.files", len(files)))
+	result, err := t.next.ScaffoldProjectFilesWithOptions(ctx, projectID, files, opts)
+	if result != nil {
+		span.SetAttributes(attribute.Int("git.failed", len(result.Failed)))
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracingAdapter) ScaffoldFromTemplates(ctx context.Context, projectID uuid.UUID, fsys fs.FS, data any) (*ScaffoldResult, error) {
+	ctx, span := t.start(ctx, "ScaffoldFromTemplates", projectID, nil)
+	result, err := t.next.ScaffoldFromTemplates(ctx, projectID, fsys, data)
+	if result != nil {
+		span.SetAttributes(attribute.Int("git.files", len(result.Succeeded)+len(result.Failed)), attribute.Int("git.failed", len(result.Failed)))
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracingAdapter) ScaffoldFromFS(ctx context.Context, projectID uuid.UUID, fsys fs.FS, root string) (*ScaffoldResult, error) {
+	ctx, span := t.start(ctx, "ScaffoldFromFS", projectID, nil, attribute.String("git.path", root))
+	result, err := t.next.ScaffoldFromFS(ctx, projectID, fsys, root)
+	if result != nil {
+		span.SetAttributes(attribute.Int("git.files", len(result.Succeeded)+len(result.Failed)), attribute.Int("git.failed", len(result.Failed)))
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracingAdapter) MergeScaffold(ctx context.Context, projectID uuid.UUID, baseFiles, newFiles []FileNode, strategy ScaffoldMergeStrategy) (*ScaffoldMergeResult, error) {
+	ctx, span := t.start(ctx, "MergeScaffold", projectID, nil, attribute.Int("git.files", len(newFiles)), attribute.String("git.strategy", string(strategy)))
+	result, err := t.next.MergeScaffold(ctx, projectID, baseFiles, newFiles, strategy)
+	if result != nil {
+		span.SetAttributes(attribute.Int("git.conflicts", len(result.Conflicts)))
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracingAdapter) SearchFiles(ctx context.Context, projectID uuid.UUID, query string, opts ...Option) ([]SearchMatch, error) {
+	ctx, span := t.start(ctx, "SearchFiles", projectID, opts)
+	matches, err := t.next.SearchFiles(ctx, projectID, query, opts...)
+	span.SetAttributes(attribute.Int("git.matches", len(matches)))
+	endSpan(span, err)
+	return matches, err
+}
+
+// start opens the span for operation, tagging it with the project and any branch or owner override
+func (t *tracingAdapter) start(ctx context.Context, operation string, projectID uuid.UUID, opts []Option, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("git.operation", operation))
+	if projectID != uuid.Nil {
+		attrs = append(attrs, attribute.String("git.project_id", projectID.String()))
+	}
+	o := newCallOptions("", opts)
+	if o.branch != "" {
+		attrs = append(attrs, attribute.String("git.branch", o.branch))
+	}
+	if o.owner != "" {
+		attrs = append(attrs, attribute.String("git.owner", o.owner))
+	}
+	return t.tracer.Start(ctx, "git."+operation, trace.WithAttributes(attrs...), trace.WithSpanKind(trace.SpanKindClient))
+}
+
+// endSpan records err on the span, if any, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End()
+}
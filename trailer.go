@@ -0,0 +1,65 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	coAuthorTrailer   = "Co-authored-by"
+	defaultJobTrailer = "Job-Id"
+)
+
+// conventionalSubject matches a subject that already has a conventional commits type, e.g. "fix(api)!: ..."
+var conventionalSubject = regexp.MustCompile(`^[a-z]+(\([^()]*\))?!?: `)
+
+// conventionalMessage prefixes message with commitType and scope unless its subject has a type already
+func conventionalMessage(message, commitType, scope string) string {
+	if commitType == "" || conventionalSubject.MatchString(message) {
+		return message
+	}
+	if scope != "" {
+		commitType += "(" + scope + ")"
+	}
+	return commitType + ": " + message
+}
+
+// trailerLine matches a git trailer such as "Job-Id: 42"
+var trailerLine = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*): ?(.*)$`)
+
+// ParseTrailers returns the trailers in the last paragraph of a commit message, in order.
+// A message whose only paragraph looks like trailers has none, matching git.
+func ParseTrailers(message string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	var trailers []Trailer
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		m := trailerLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: m[1], Value: m[2]})
+	}
+	return trailers
+}
+
+// appendTrailers adds trailers to message, extending an existing trailer block if there is one.
+// Newlines in values are folded to spaces so each trailer stays on one line.
+func appendTrailers(message string, trailers []Trailer) string {
+	if len(trailers) == 0 {
+		return message
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(message, "\n"))
+	if ParseTrailers(message) == nil {
+		b.WriteString("\n")
+	}
+	for _, t := range trailers {
+		b.WriteString("\n" + t.Key + ": " + strings.Join(strings.Fields(t.Value), " "))
+	}
+	return b.String()
+}
@@ -1,46 +1,944 @@
 package git
 
 import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"iter"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"code.gitea.io/sdk/gitea"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/google/uuid"
 )
 
 const (
-	FileTypeFile    FileType = "file"
-	FileTypeDir     FileType = "dir"
-	FileTypeSymlink FileType = "symlink"
+	FileTypeFile      FileType = "file"
+	FileTypeDir       FileType = "dir"
+	FileTypeSymlink   FileType = "symlink"
+	FileTypeSubmodule FileType = "submodule"
+
+	FileModeRegular    FileMode = "100644"
+	FileModeExecutable FileMode = "100755"
+	FileModeSymlink    FileMode = "120000"
+	FileModeDir        FileMode = "040000"
+	FileModeSubmodule  FileMode = "160000"
+
+	PresetGo     Preset = "go"
+	PresetNode   Preset = "node"
+	PresetPython Preset = "python"
+	PresetJava   Preset = "java"
+	PresetRust   Preset = "rust"
+	PresetLFS    Preset = "lfs" // Git LFS attributes for media, archives and fonts
+
+	LineEndingLF   LineEnding = "lf"
+	LineEndingCRLF LineEnding = "crlf"
+
+	FileOperationCreate FileOperation = "create"
+	FileOperationUpdate FileOperation = "update"
+	FileOperationDelete FileOperation = "delete"
+
+	DiffStatusAdded    DiffStatus = "added"
+	DiffStatusModified DiffStatus = "modified"
+	DiffStatusDeleted  DiffStatus = "deleted"
+
+	MergeStrategyMerge       MergeStrategy = "merge"
+	MergeStrategyRebase      MergeStrategy = "rebase"
+	MergeStrategyRebaseMerge MergeStrategy = "rebase-merge"
+	MergeStrategySquash      MergeStrategy = "squash"
+
+	ReviewApprove        ReviewEvent = "APPROVED"
+	ReviewComment        ReviewEvent = "COMMENT"
+	ReviewRequestChanges ReviewEvent = "REQUEST_CHANGES"
+
+	PlanCreate   PlanAction = "create"
+	PlanUpdate   PlanAction = "update"
+	PlanDelete   PlanAction = "delete"
+	PlanConflict PlanAction = "conflict"
+	PlanNoop     PlanAction = "no-op"
+
+	ScaffoldMergeReport  ScaffoldMergeStrategy = "report"  // Leave conflicting files as they are
+	ScaffoldMergeMarkers ScaffoldMergeStrategy = "markers" // Write both sides between conflict markers
+	ScaffoldMergeOurs    ScaffoldMergeStrategy = "ours"    // Keep the repository side of conflicting changes
+	ScaffoldMergeTheirs  ScaffoldMergeStrategy = "theirs"  // Take the template side of conflicting changes
+
+	CommitStatePending CommitState = "pending"
+	CommitStateSuccess CommitState = "success"
+	CommitStateFailure CommitState = "failure"
+	CommitStateError   CommitState = "error"
+	CommitStateWarning CommitState = "warning"
+
+	PermissionRead  Permission = "read"
+	PermissionWrite Permission = "write"
+	PermissionAdmin Permission = "admin"
+
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+	ArchiveZip   ArchiveFormat = "zip"
+
+	// Limits bounds reported by LimitError.Limit
+	LimitFileSize      = "file_size"
+	LimitRepoSize      = "repo_size"
+	LimitScaffoldFiles = "scaffold_files"
+
+	// Gitea webhook event names accepted by WebhookOptions.Events
+	WebhookEventPush        = "push"
+	WebhookEventPullRequest = "pull_request"
+	WebhookEventCreate      = "create"
+	WebhookEventDelete      = "delete"
+	WebhookEventRelease     = "release"
+)
+
+// Sentinel errors returned (wrapped) by every adapter; match them with errors.Is
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrUnavailable  = errors.New("unavailable")    // the circuit breaker is open, see GitConfig.BreakerThreshold
+	ErrNotModified  = errors.New("not modified")   // the SHA passed to WithIfNoneMatch is still current
+	ErrLimit        = errors.New("limit exceeded") // a write would break a configured Limits bound, see LimitError
+	ErrInvalidPath  = errors.New("invalid path")   // a path is malformed or leaves the repository
+	ErrUnsupported  = errors.ErrUnsupported        // the backend has no API for the operation, e.g. ResolveComment on Gitea
 )
 
 type (
 	// FileType indicates if it is a file or directory
 	FileType string
 
+	// FileMode is the git mode of a tree entry, in git's octal notation
+	FileMode string
+
+	// Preset selects the .gitignore and .gitattributes rules of a language or tool, see GitIgnore
+	Preset string
+
+	// LineEnding is the newline sequence Normalization converts text to
+	LineEnding string
+
+	// FileOperation is the kind of change applied to a path in a multi-file commit
+	FileOperation string
+
+	// DiffStatus is how a file changed between two refs
+	DiffStatus string
+
+	// ArchiveFormat is the container format of a repository archive
+	ArchiveFormat string
+
+	// CommitState is the state of a commit status check
+	CommitState string
+
+	// Permission is the access level granted to a collaborator
+	Permission string
+
+	// MergeStrategy selects how a pull request is merged
+	MergeStrategy string
+
+	// ReviewEvent is the verdict SubmitReview gives a pull request
+	ReviewEvent string
+
+	// PlanAction is what committing a change would do to its path
+	PlanAction string
+
+	// ScaffoldMergeStrategy selects how MergeScaffold resolves conflicts; empty means ScaffoldMergeReport
+	ScaffoldMergeStrategy string
+
+	// Adapter is the file and repository surface shared by all Git backends
+	Adapter interface {
+		GetFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error)
+		GetFiles(ctx context.Context, projectID uuid.UUID, paths []string, opts ...Option) (map[string]*FileNode, error)
+		StatFile(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) (*FileNode, error)
+		ListFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error)
+		ListFilesRecursive(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) ([]FileNode, error)
+		IterateFiles(ctx context.Context, projectID uuid.UUID, path string, opts ...Option) iter.Seq2[FileNode, error]
+		OpenFile(ctx context.Context, projectID uuid.UUID, path, ref string, opts ...Option) (io.ReadCloser, error)
+		CommitFile(ctx context.Context, projectID uuid.UUID, path, content, message string, opts ...Option) error
+		CommitFileBytes(ctx context.Context, projectID uuid.UUID, path string, content []byte, message string, opts ...Option) error
+		WriteFile(ctx context.Context, projectID uuid.UUID, path string, r io.Reader, message string, opts ...Option) error
+		CommitFiles(ctx context.Context, projectID uuid.UUID, files []FileChange, message string, opts ...Option) error
+		NewChangeSet(projectID uuid.UUID) *ChangeSet
+		PlanChanges(ctx context.Context, projectID uuid.UUID, changes []FileChange, opts ...Option) (*Plan, error)
+		DeleteFile(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error
+		DeletePath(ctx context.Context, projectID uuid.UUID, path, message string, opts ...Option) error
+		SearchFiles(ctx context.Context, projectID uuid.UUID, query string, opts ...Option) ([]SearchMatch, error)
+		MoveFile(ctx context.Context, projectID uuid.UUID, oldPath, newPath, message string, opts ...Option) error
+		CopyFile(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, srcPath, dstPath, message string) error
+		CopyFiles(ctx context.Context, srcProjectID, dstProjectID uuid.UUID, paths map[string]string, message string) error
+		ImportArchive(ctx context.Context, projectID uuid.UUID, r io.Reader, message string, opts ...Option) error
+		CreateRepository(ctx context.Context, projectID uuid.UUID, opts ...Option) (string, error)
+		RepositoryExists(ctx context.Context, projectID uuid.UUID, opts ...Option) (bool, error)
+		ScaffoldProjectFiles(ctx context.Context, projectID uuid.UUID, files []FileNode) (*ScaffoldResult, error)
+		ScaffoldProjectFilesWithOptions(ctx context.Context, projectID uuid.UUID, files []FileNode, opts ScaffoldOptions) (*ScaffoldResult, error)
+		ScaffoldFromTemplates(ctx context.Context, projectID uuid.UUID, fsys fs.FS, data any) (*ScaffoldResult, error)
+		ScaffoldFromFS(ctx context.Context, projectID uuid.UUID, fsys fs.FS, root string) (*ScaffoldResult, error)
+		MergeScaffold(ctx context.Context, projectID uuid.UUID, baseFiles, newFiles []FileNode, strategy ScaffoldMergeStrategy) (*ScaffoldMergeResult, error)
+		Ping(ctx context.Context) error
+		ServerInfo(ctx context.Context) (*ServerInfo, error)
+	}
+
 	GiteaAdapter struct {
-		client   *gitea.Client
+		version  string       // server version, empty when unparsable
+		http     *http.Client // shared by every SDK client and doJSON
+		logger   *slog.Logger
 		identity *gitea.Identity
+		signer   gogit.Signer // nil unless GitConfig.SigningKey is set
+		creds    CredentialProvider
+		namer    RepoNamer
+		debug    *atomic.Bool // see SetHTTPDebug
+		watchers watchers     // see NotifyChange
 		env      *GitConfig
 	}
 
+	// LocalGitAdapter serves projects from bare repositories on disk, for offline and dev use
+	LocalGitAdapter struct {
+		mu     sync.Mutex // serializes ref updates
+		logger *slog.Logger
+		signer gogit.Signer // nil unless LocalGitConfig.SigningKey is set
+		env    *LocalGitConfig
+	}
+
+	// MemoryAdapter keeps repositories in memory, for unit tests that must not touch the network
+	MemoryAdapter struct {
+		mu     sync.RWMutex
+		logger *slog.Logger
+		owner  string
+		branch string
+		repos  map[uuid.UUID]map[string]map[string]string // projectID -> branch -> path -> content
+		execs  map[uuid.UUID]map[string]map[string]bool   // projectID -> branch -> executable paths
+	}
+
+	// DryRunAdapter plans mutating calls instead of applying them, see NewDryRunAdapter
+	DryRunAdapter struct {
+		mu      sync.Mutex
+		next    Adapter
+		logger  *slog.Logger
+		planned []PlannedOperation
+	}
+
+	// Plan is the outcome CommitFiles would have for a set of changes, see Adapter.PlanChanges
+	Plan struct {
+		ProjectID uuid.UUID       `json:"project_id"`
+		Branch    string          `json:"branch,omitempty"` // Empty for the configured branch
+		Changes   []PlannedChange `json:"changes"`
+	}
+
+	// PlannedChange is the action planned for one change
+	PlannedChange struct {
+		Action   PlanAction `json:"action"`
+		Path     string     `json:"path"`
+		FromPath string     `json:"from_path,omitempty"`
+		SHA      string     `json:"sha,omitempty"`    // Current blob SHA of the path, empty when it does not exist
+		Reason   string     `json:"reason,omitempty"` // Why the change conflicts
+	}
+
+	// PlannedOperation is a change a DryRunAdapter recorded instead of applying
+	PlannedOperation struct {
+		Method    string        `json:"method"` // Adapter method that was called, e.g. "CommitFile"
+		ProjectID uuid.UUID     `json:"project_id"`
+		Operation FileOperation `json:"operation"` // create, update or delete; create for CreateRepository
+		Path      string        `json:"path,omitempty"`
+		FromPath  string        `json:"from_path,omitempty"`
+		Branch    string        `json:"branch,omitempty"` // Empty for the configured branch
+		Message   string        `json:"message,omitempty"`
+	}
+
 	// FileNode represents a file or directory in the project
 	FileNode struct {
-		Name     string     `json:"name"`
+		Name         string      `json:"name"`
+		Path         string      `json:"path"`
+		Type         FileType    `json:"type"`
+		Mode         FileMode    `json:"mode,omitempty"`          // Empty where the API does not report it, e.g. Gitea GetFile and ListFiles
+		Target       *string     `json:"target,omitempty"`        // `target` is populated when `type` is `symlink`, otherwise null
+		SubmoduleURL string      `json:"submodule_url,omitempty"` // Remote from .gitmodules when `type` is `submodule`; SHA is then the pinned commit
+		SHA          string      `json:"sha"`
+		Size         int64       `json:"size"`
+		Content      *string     `json:"content,omitempty"` // Content is empty for directories or list operations
+		Bytes        []byte      `json:"bytes,omitempty"`   // Bytes holds the raw content of binary files, base64 encoded in JSON
+		IsBinary     bool        `json:"is_binary,omitempty"`
+		MimeType     string      `json:"mime_type,omitempty"` // Detected when the content is read, see DetectFileType
+		Language     string      `json:"language,omitempty"`  // e.g. "Go" or "TypeScript", for picking an editor mode
+		LFS          *LFSPointer `json:"lfs,omitempty"`       // Set when the content is a Git LFS pointer rather than the object
+		Children     []FileNode  `json:"children,omitempty"`  // Children is populated for directories when listing recursively
+	}
+
+	// LFSPointer identifies a Git LFS object by the SHA-256 of its content
+	LFSPointer struct {
+		OID  string `json:"oid"`
+		Size int64  `json:"size"`
+	}
+
+	// FileChange is a single path change applied by CommitFiles
+	FileChange struct {
+		Operation FileOperation `json:"operation,omitempty"` // Empty means create or update, resolved against the branch
+		Path      string        `json:"path"`
+		FromPath  string        `json:"from_path,omitempty"` // Source path when the change renames a file
+		Content   string        `json:"content,omitempty"`
+		Bytes     []byte        `json:"bytes,omitempty"` // Binary-safe content; used instead of Content when set
+		SHA       string        `json:"sha,omitempty"`   // Current blob SHA for update/delete; looked up when empty
+		Mode      FileMode      `json:"mode,omitempty"`  // FileModeRegular or FileModeExecutable; empty keeps the mode of an existing file
+	}
+
+	// Branch is a branch of a project repository
+	Branch struct {
+		Name      string    `json:"name"`
+		CommitSHA string    `json:"commit_sha"` // SHA of the branch tip
+		Protected bool      `json:"protected"`
+		UpdatedAt time.Time `json:"updated_at"` // Timestamp of the tip commit
+	}
+
+	// HousekeepingPolicy selects the maintenance Housekeep performs. The configured branch and
+	// protected branches are never deleted.
+	HousekeepingPolicy struct {
+		GC           bool          // Repack and prune the repository; on Gitea this runs the instance-wide git_gc_repos cron task and needs an admin token
+		StaleAfter   time.Duration // Delete branches whose tip commit is older than this; zero keeps them
+		DeleteMerged bool          // Delete branches with no commits beyond the configured branch
+		Keep         []string      // Branches never deleted
+		DryRun       bool          // Report the branches that would be deleted without deleting them
+	}
+
+	// HousekeepingReport is what Housekeep did, or would do under HousekeepingPolicy.DryRun
+	HousekeepingReport struct {
+		DeletedBranches  []string `json:"deleted_branches"`
+		GarbageCollected bool     `json:"garbage_collected"`
+	}
+
+	// BranchProtection is the protection rule applied by ProtectBranch
+	BranchProtection struct {
+		RequiredApprovals      int64    // Approving reviews needed before a pull request can be merged
+		StatusChecks           []string // Commit status contexts that must pass before merging
+		PushUsers              []string // Only these users may push directly; anyone with write access when empty
+		BlockOnRejectedReviews bool     // Block merging while a review requests changes
+		BlockOnOutdatedBranch  bool     // Block merging when the head branch is behind the base
+		DismissStaleApprovals  bool     // Dismiss approvals when new commits are pushed
+		RequireSignedCommits   bool
+	}
+
+	// RepoSettings is applied by UpdateRepoSettings; nil and empty fields are left as they are
+	RepoSettings struct {
+		DefaultMergeStyle      MergeStrategy // Strategy preselected in the merge button
+		AllowMerge             *bool
+		AllowRebase            *bool
+		AllowRebaseMerge       *bool
+		AllowSquash            *bool
+		DeleteBranchAfterMerge *bool // Preselects deleting the head branch when merging
+		HasIssues              *bool
+		HasWiki                *bool
+		HasProjects            *bool
+		HasPullRequests        *bool // The merge options need pull requests enabled
+	}
+
+	// PullRequest is a pull request of a project repository
+	PullRequest struct {
+		Index          int64  `json:"index"`
+		Title          string `json:"title"`
+		Body           string `json:"body"`
+		Head           string `json:"head"`
+		Base           string `json:"base"`
+		State          string `json:"state"` // open or closed
+		Merged         bool   `json:"merged"`
+		MergeCommitSHA string `json:"merge_commit_sha,omitempty"`
+		HTMLURL        string `json:"html_url"`
+	}
+
+	// Review is a review of a pull request
+	Review struct {
+		ID          int64       `json:"id"`
+		Reviewer    string      `json:"reviewer"` // User or team login
+		State       ReviewEvent `json:"state"`    // PENDING and REQUEST_REVIEW besides the submitted events
+		Body        string      `json:"body"`
+		CommitSHA   string      `json:"commit_sha"` // Head commit the review was given on
+		Stale       bool        `json:"stale"`      // The pull request changed since
+		Official    bool        `json:"official"`   // Counts towards BranchProtection.RequiredApprovals
+		Dismissed   bool        `json:"dismissed"`
+		SubmittedAt time.Time   `json:"submitted_at"`
+		HTMLURL     string      `json:"html_url"`
+	}
+
+	// PullComment is a comment on a pull request; Path and Line are set on line-anchored ones
+	PullComment struct {
+		ID        int64     `json:"id"`
+		ReviewID  int64     `json:"review_id,omitempty"` // Review the line-anchored comment belongs to
+		Author    string    `json:"author"`
+		Body      string    `json:"body"`
+		Path      string    `json:"path,omitempty"`
+		Line      int64     `json:"line,omitempty"`       // Line in the new version of Path
+		CommitSHA string    `json:"commit_sha,omitempty"` // Commit the comment was made on
+		Resolved  bool      `json:"resolved"`
+		HTMLURL   string    `json:"html_url"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// LineComment anchors a comment to a line of a file the pull request changes
+	LineComment struct {
+		Path string
+		Line int64 // Line in the new version of Path
+		Body string
+	}
+
+	// Repository is a repository found under an owner. ProjectID is uuid.Nil when the name
+	// is not a project ID, e.g. for repositories created outside the orchestrator.
+	Repository struct {
+		ProjectID     uuid.UUID `json:"project_id"`
+		Owner         string    `json:"owner"`
+		Name          string    `json:"name"`
+		FullName      string    `json:"full_name"`
+		Description   string    `json:"description,omitempty"`
+		Website       string    `json:"website,omitempty"`
+		DefaultBranch string    `json:"default_branch"`
+		Private       bool      `json:"private"`
+		Archived      bool      `json:"archived"`
+		Empty         bool      `json:"empty"`
+		HTMLURL       string    `json:"html_url"`
+		CloneURL      string    `json:"clone_url"`
+		CreatedAt     time.Time `json:"created_at"`
+		UpdatedAt     time.Time `json:"updated_at"`
+	}
+
+	// RepoStats summarises the health of a project repository, see GetRepoStats
+	RepoStats struct {
+		ProjectID    uuid.UUID        `json:"project_id"`
+		Size         int64            `json:"size"`    // Bytes on disk as Gitea reports it, in KiB steps
+		Commits      int64            `json:"commits"` // Commits reachable from the default branch
+		Branches     int64            `json:"branches"`
+		LastActivity time.Time        `json:"last_activity"`
+		Languages    map[string]int64 `json:"languages"` // Bytes of code per language, as detected by Gitea
+	}
+
+	// SearchMatch is a line containing the query of a SearchFiles call
+	SearchMatch struct {
+		Path string `json:"path"`
+		Line int    `json:"line"` // 1-based
+		Text string `json:"text"`
+	}
+
+	// SearchOptions narrows SearchRepositories. The zero value searches the configured owner.
+	SearchOptions struct {
+		Owner           string // User or organization to search, defaults to the configured owner
+		Topic           bool   // Match the query against topics instead of names
+		ExcludeArchived bool
+		Limit           int // Maximum number of results, 0 for all
+		Page            int // 1-based page of Limit results (50 when Limit is 0); 0 starts from the first
+	}
+
+	// Issue is an issue of a project repository
+	Issue struct {
+		Index     int64     `json:"index"`
+		Title     string    `json:"title"`
+		Body      string    `json:"body"`
+		State     string    `json:"state"` // open or closed
+		Labels    []string  `json:"labels,omitempty"`
+		HTMLURL   string    `json:"html_url"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	// Label categorizes issues and pull requests
+	Label struct {
+		ID          int64  `json:"id"`
+		Name        string `json:"name"`
+		Color       string `json:"color"` // e.g. "#00aabb"
+		Description string `json:"description,omitempty"`
+	}
+
+	// Milestone groups issues and pull requests towards a goal
+	Milestone struct {
+		ID          int64      `json:"id"`
+		Title       string     `json:"title"`
+		Description string     `json:"description,omitempty"`
+		State       string     `json:"state"` // open or closed
+		DueAt       *time.Time `json:"due_at,omitempty"`
+	}
+
+	// Tag is a git tag of a project repository
+	Tag struct {
+		Name      string `json:"name"`
+		CommitSHA string `json:"commit_sha"`
+		Message   string `json:"message,omitempty"` // Message is set for annotated tags
+	}
+
+	// ReleaseOptions describes a release to create
+	ReleaseOptions struct {
+		TagName    string // Tag to publish; created from Target when missing
+		Target     string // Branch or commit SHA for a new tag, defaults to the configured branch
+		Title      string // Defaults to TagName
+		Notes      string
+		Draft      bool
+		Prerelease bool
+	}
+
+	// Release is a published release of a project repository
+	Release struct {
+		ID         int64          `json:"id"`
+		TagName    string         `json:"tag_name"`
+		Title      string         `json:"title"`
+		Notes      string         `json:"notes"`
+		Draft      bool           `json:"draft"`
+		Prerelease bool           `json:"prerelease"`
+		HTMLURL    string         `json:"html_url"`
+		Assets     []ReleaseAsset `json:"assets,omitempty"`
+	}
+
+	// ReleaseAsset is a file attached to a release
+	ReleaseAsset struct {
+		ID          int64  `json:"id"`
+		Name        string `json:"name"`
+		Size        int64  `json:"size"`
+		DownloadURL string `json:"download_url"`
+	}
+
+	// WebhookOptions describes a webhook to register
+	WebhookOptions struct {
+		URL          string   // Endpoint receiving the JSON payloads
+		Secret       string   // HMAC-SHA256 key used to sign payloads, sent as X-Gitea-Signature
+		Events       []string // WebhookEvent* names, defaults to push and pull_request
+		BranchFilter string   // Glob limiting push events to matching branches, all branches when empty
+	}
+
+	// Webhook is a webhook registered on a project repository
+	Webhook struct {
+		ID           int64     `json:"id"`
+		URL          string    `json:"url"`
+		Events       []string  `json:"events"`
+		BranchFilter string    `json:"branch_filter,omitempty"`
+		Active       bool      `json:"active"`
+		CreatedAt    time.Time `json:"created_at"`
+	}
+
+	// DeployKey is an SSH key with access to a single repository
+	DeployKey struct {
+		ID          int64     `json:"id"`
+		Title       string    `json:"title"`
+		Key         string    `json:"key"`
+		Fingerprint string    `json:"fingerprint"`
+		ReadOnly    bool      `json:"read_only"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+
+	// MirrorCredentials authenticate pushes to a mirror remote; Password is usually an access token
+	MirrorCredentials struct {
+		Username string
+		Password string
+	}
+
+	// PushMirror is a remote the repository is pushed to
+	PushMirror struct {
+		RemoteName   string `json:"remote_name"` // Gitea-assigned name, used to remove the mirror
+		RemoteURL    string `json:"remote_url"`
+		Interval     string `json:"interval"`
+		SyncOnCommit bool   `json:"sync_on_commit"`
+		LastUpdate   string `json:"last_update,omitempty"`
+		LastError    string `json:"last_error,omitempty"`
+	}
+
+	// CommitListOptions selects a page of commit history
+	CommitListOptions struct {
+		Ref   string // Branch, tag or SHA to start from, defaults to the configured branch
+		Page  int    // 1-based page number
+		Limit int    // Page size, server default when zero
+	}
+
+	// Commit is a single commit in a project's history
+	Commit struct {
+		SHA         string    `json:"sha"`
+		Message     string    `json:"message"`
+		AuthorName  string    `json:"author_name"`
+		AuthorEmail string    `json:"author_email"`
+		Timestamp   time.Time `json:"timestamp"`
+		ParentSHAs  []string  `json:"parent_shas,omitempty"`
+		HTMLURL     string    `json:"html_url,omitempty"`
+		Trailers    []Trailer `json:"trailers,omitempty"` // Parsed from Message, see WithTrailer
+		Verified    bool      `json:"verified,omitempty"` // Signature checked by Gitea; always false elsewhere
+	}
+
+	// ChangeEvent reports that a branch moved, see GiteaAdapter.WatchRepository
+	ChangeEvent struct {
+		ProjectID  uuid.UUID `json:"project_id"`
+		Branch     string    `json:"branch"`
+		BeforeSHA  string    `json:"before_sha"` // Empty when the branch was created
+		AfterSHA   string    `json:"after_sha"`  // Empty when the branch was deleted
+		DetectedAt time.Time `json:"detected_at"`
+	}
+
+	// AuditRecord describes one mutating Adapter call, see NewAuditAdapter
+	AuditRecord struct {
+		Time      time.Time     `json:"time"`
+		Operation string        `json:"operation"`
+		ProjectID uuid.UUID     `json:"project_id"`
+		Owner     string        `json:"owner,omitempty"`  // WithOwner; empty for the configured owner
+		Branch    string        `json:"branch,omitempty"` // WithBranch; empty for the configured branch
+		Paths     []string      `json:"paths,omitempty"`
+		CommitSHA string        `json:"commit_sha,omitempty"` // Last commit made; the memory adapter has none
+		Message   string        `json:"message,omitempty"`    // Commit message including trailers
+		Author    string        `json:"author,omitempty"`     // WithAuthor as "name <email>"; empty for the adapter identity
+		Sudo      string        `json:"sudo,omitempty"`       // ContextWithSudo user
+		Outcome   string        `json:"outcome"`              // One of the Outcome constants
+		Error     string        `json:"error,omitempty"`
+		Duration  time.Duration `json:"duration"`
+	}
+
+	// HTTPExchange is one request/response pair captured while GitConfig.DebugHTTP is on.
+	// Credentials are redacted and bodies are cut at 64 KiB; streamed request bodies are not captured.
+	HTTPExchange struct {
+		Method         string
+		URL            string
+		RequestHeader  http.Header
+		RequestBody    []byte
+		Status         int // 0 when the request failed
+		ResponseHeader http.Header
+		ResponseBody   []byte
+		Duration       time.Duration
+		Err            string
+	}
+
+	// ServerInfo describes the backend behind an adapter, see Adapter.ServerInfo
+	ServerInfo struct {
+		Backend         string `json:"backend"`                 // "gitea", "local" or "memory"
+		Version         string `json:"version,omitempty"`       // Gitea server version
+		User            string `json:"user,omitempty"`          // Login the credentials belong to
+		MaxPageSize     int    `json:"max_page_size,omitempty"` // Largest page a Gitea listing returns
+		DefaultPageSize int    `json:"default_page_size,omitempty"`
+		MaxBlobSize     int64  `json:"max_blob_size,omitempty"` // Largest blob the Gitea API returns inline
+		GitHTTP         bool   `json:"git_http"`                // Gitea serves git over HTTP, needed for signing, modes and submodules
+		LFS             bool   `json:"lfs"`
+	}
+
+	// Trailer is a "Key: value" line at the end of a commit message
+	Trailer struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
+	// BlameRange attributes lines StartLine through EndLine (1-based, inclusive) of a file
+	// to the commit that last changed them
+	BlameRange struct {
+		StartLine   int       `json:"start_line"`
+		EndLine     int       `json:"end_line"`
+		SHA         string    `json:"sha"`
+		AuthorName  string    `json:"author_name"`
+		AuthorEmail string    `json:"author_email"`
+		Timestamp   time.Time `json:"timestamp"`
+	}
+
+	// CommitStatus is a check result reported on a commit
+	CommitStatus struct {
+		State       CommitState `json:"state"`
+		Context     string      `json:"context"` // Check name, e.g. "ci/build"
+		Description string      `json:"description,omitempty"`
+		TargetURL   string      `json:"target_url,omitempty"` // Link to the build or report
+	}
+
+	// Diff is the set of file changes between two refs
+	Diff struct {
+		Base  string     `json:"base"`
+		Head  string     `json:"head"`
+		Files []FileDiff `json:"files"`
+	}
+
+	// Comparison relates two refs, see CompareRefs. Files carry no Patch; use GetDiff for patches.
+	Comparison struct {
+		Base     string     `json:"base"`
+		Head     string     `json:"head"`
+		AheadBy  int        `json:"ahead_by"`  // Commits in head that base lacks
+		BehindBy int        `json:"behind_by"` // Commits in base that head lacks
+		Files    []FileDiff `json:"files"`     // Paths whose content differs between the two trees
+	}
+
+	// FileDiff is the change of a single file, with its unified diff text
+	FileDiff struct {
 		Path     string     `json:"path"`
-		Type     FileType   `json:"type"`
-		Target   *string    `json:"target,omitempty"` // `target` is populated when `type` is `symlink`, otherwise null
-		SHA      string     `json:"sha"`
-		Size     int64      `json:"size"`
-		Content  *string    `json:"content,omitempty"`  // Content is empty for directories or list operations
-		Children []FileNode `json:"children,omitempty"` // Children is populated for directories when listing recursively
+		Status   DiffStatus `json:"status"`
+		OldSHA   string     `json:"old_sha,omitempty"`
+		NewSHA   string     `json:"new_sha,omitempty"`
+		IsBinary bool       `json:"is_binary"`
+		Patch    string     `json:"patch"` // Unified diff, headers only for binary files
+	}
+
+	// CreateRepositoryOptions overrides the GitConfig defaults for one CreateRepository call, see
+	// WithRepositoryOptions. Zero fields keep the defaults.
+	CreateRepositoryOptions struct {
+		Description   string   // defaults to "Managed by GitAPI"
+		DefaultBranch string   // defaults to GitConfig.Branch; later calls still need WithBranch for it
+		Topics        []string // Gitea only, set once the repository exists
+		Private       *bool    // overrides GitConfig.CreateRepoPrivate
+		AutoInit      *bool    // overrides GitConfig.CreateRepoInit
+		Presets       []Preset // .gitignore and .gitattributes committed once the repository exists, see PresetFiles
+
+		// Readme is a text/template executed with ReadmeData and committed as README.md; empty keeps
+		// the default README of AutoInit. License names a Gitea license template, e.g. "MIT" or
+		// "Apache-2.0", committed as LICENSE with the year and owner filled in; it implies AutoInit
+		// and is skipped by the local and memory adapters, which have no templates.
+		Readme   string
+		License  string
+		Metadata map[string]string // Project metadata for Readme, e.g. the project title or team
+	}
+
+	// ReadmeData is what CreateRepositoryOptions.Readme is executed with
+	ReadmeData struct {
+		ProjectID   uuid.UUID
+		Name        string // Repository name
+		Owner       string
+		Description string
+		License     string
+		Metadata    map[string]string
+	}
+
+	// ScaffoldOptions tunes how ScaffoldProjectFilesWithOptions commits files.
+	// The zero value commits serially without retries.
+	ScaffoldOptions struct {
+		Workers    int           // Number of files committed in parallel
+		Retries    int           // Extra attempts per file after a failed commit
+		RetryDelay time.Duration // Initial backoff between attempts, doubled on each retry (default 1s)
+		Interval   time.Duration // Minimum delay between commits across all workers, to stay under API rate limits
+		Progress   ProgressFunc  // Called after each file is committed, skipped or has failed
+		Presets    []Preset      // Adds .gitignore and .gitattributes, see PresetFiles, unless the files include them
+
+		// Checkpoint, when set, records every committed path so a rerun after a crash skips them.
+		// It is cleared once a run finishes without failures.
+		Checkpoint ScaffoldCheckpoint
+	}
+
+	// ScaffoldCheckpoint persists the paths a scaffold run has committed, with their blob SHA,
+	// so a file whose content changed between runs is committed again
+	ScaffoldCheckpoint interface {
+		Load(ctx context.Context, projectID uuid.UUID) (map[string]string, error) // path -> blob SHA
+		Save(ctx context.Context, projectID uuid.UUID, path, sha string) error
+		Clear(ctx context.Context, projectID uuid.UUID) error
+	}
+
+	// FileCheckpoint is a ScaffoldCheckpoint keeping one append-only file per project, see NewFileCheckpoint
+	FileCheckpoint struct {
+		mu  sync.Mutex
+		dir string
+	}
+
+	// Progress reports how far a scaffold or archive import has got
+	Progress struct {
+		Done  int    // Files processed so far
+		Total int    // Files in the operation; 0 while an archive is still being read
+		Path  string // File just processed
+		Bytes int64  // Content size of the files processed so far
+	}
+
+	// ProgressFunc receives Progress updates. Calls are serialized, so it need not be safe
+	// for concurrent use, but it runs on the worker goroutine and should return quickly.
+	ProgressFunc func(Progress)
+
+	// TemplateData is a ready-made data context for ScaffoldFromTemplates, e.g. {{.Name}} or {{.Values.port}}
+	TemplateData struct {
+		ProjectID uuid.UUID
+		Name      string
+		Owner     string
+		Values    map[string]any
+	}
+
+	// ChangeSet stages puts, deletes and moves to apply as one commit, see Adapter.NewChangeSet.
+	// Its methods are not safe for concurrent use.
+	ChangeSet struct {
+		adapter   Adapter
+		projectID uuid.UUID
+		changes   []FileChange
+		errs      []error // Invalid staging calls, reported by Validate
+	}
+
+	// ScaffoldMergeResult reports how MergeScaffold resolved every path of either template version
+	ScaffoldMergeResult struct {
+		Created   []string // New template files, and deleted files re-created by ScaffoldMergeTheirs
+		Updated   []string // Files given the template changes, merged with any repository edits
+		Deleted   []string // Files dropped from the template that the repository had not edited
+		Unchanged []string // Files already up to date or whose template did not change
+		Conflicts []string // Files where template changes collide with repository edits
+	}
+
+	// ScaffoldResult reports the outcome of every path in a scaffold run
+	ScaffoldResult struct {
+		Succeeded []string         `json:"succeeded"` // Every path now in place: created, updated or skipped
+		Created   []string         `json:"created,omitempty"`
+		Updated   []string         `json:"updated,omitempty"`
+		Skipped   []string         `json:"skipped,omitempty"` // Content already matched, nothing committed
+		Failed    []string         `json:"failed"`
+		Errors    map[string]error `json:"-"` // Errors holds the failure for each path in Failed
+	}
+
+	// PropagateOptions tunes how PropagateChange applies a change set across projects
+	PropagateOptions struct {
+		Message    string   // Commit message, required
+		BaseBranch string   // Branch to change, or to merge into with PullRequest; defaults to the configured branch
+		Workers    int      // Number of projects handled in parallel
+		Options    []Option // Passed to every commit, e.g. WithTrailer or WithAuthor
+
+		// PullRequest commits to HeadBranch, created from BaseBranch, and opens a pull request titled
+		// Title (default Message) with Body instead of committing to BaseBranch directly
+		PullRequest bool
+		HeadBranch  string
+		Title       string
+		Body        string
+	}
+
+	// PropagateResult reports the outcome of every project in a PropagateChange run
+	PropagateResult struct {
+		Succeeded    []uuid.UUID                `json:"succeeded"`
+		Failed       []uuid.UUID                `json:"failed"`
+		PullRequests map[uuid.UUID]*PullRequest `json:"pull_requests,omitempty"` // Opened with PropagateOptions.PullRequest
+		Errors       map[uuid.UUID]error        `json:"-"`                       // Errors holds the failure for each project in Failed
+	}
+
+	// changeFilesOptions is the request body of POST /repos/{owner}/{repo}/contents
+	changeFilesOptions struct {
+		gitea.FileOptions
+		Files []changeFileOperation `json:"files"`
+	}
+
+	changeFileOperation struct {
+		Operation string `json:"operation"`
+		Path      string `json:"path"`
+		Content   string `json:"content,omitempty"` // base64 encoded
+		SHA       string `json:"sha,omitempty"`
+		FromPath  string `json:"from_path,omitempty"`
 	}
 
 	// GitConfig holds Gitea connection settings
 	GitConfig struct {
 		BaseURL           string `envconfig:"ORCHESTRATOR_GIT_BASE_URL" required:"true"` // e.g., "http://gitea.default.svc.cluster.local:3000"
-		Token             string `envconfig:"ORCHESTRATOR_GIT_TOKEN"    required:"true"` // Personal Access Token for Gitea
+		Token             string `envconfig:"ORCHESTRATOR_GIT_TOKEN"`                    // Personal Access Token for Gitea
+		TokenFile         string `envconfig:"ORCHESTRATOR_GIT_TOKEN_FILE"`               // Read instead of Token and re-read when it changes
 		IdName            string `envconfig:"ORCHESTRATOR_GIT_ID_NAME"      default:"ZamineBazi Orchestrator"`
 		IdMail            string `envconfig:"ORCHESTRATOR_GIT_ID_EMAIL"     default:"bot@zaminebazi.com"`
 		Owner             string `envconfig:"ORCHESTRATOR_GIT_OWNER_NAME"   default:"zaminebazi"`
 		Branch            string `envconfig:"ORCHESTRATOR_GIT_BRANCH_NAME"  default:"main"`
 		CreateRepoPrivate bool   `envconfig:"ORCHESTRATOR_GIT_REPO_PRIVATE" default:"false"`
 		CreateRepoInit    bool   `envconfig:"ORCHESTRATOR_GIT_REPO_INIT"    default:"true"`
+
+		// Repositories are named the project ID unless RepoNamer is set, e.g. SlugRepoNamer, or RepoPrefix
+		// is, which gives PrefixRepoNamer(RepoPrefix). Changing either does not rename existing repositories.
+		RepoPrefix string    `envconfig:"ORCHESTRATOR_GIT_REPO_PREFIX"`
+		RepoNamer  RepoNamer `ignored:"true"`
+
+		// RawFileThreshold makes GetFile try the raw endpoint first and use it for files of at least
+		// that many bytes, skipping the base64 JSON of the contents API; smaller files then cost a
+		// second request. 0 keeps the contents API, which still falls back to the raw endpoint for
+		// files beyond Gitea's blob size limit.
+		RawFileThreshold int64 `envconfig:"ORCHESTRATOR_GIT_RAW_FILE_THRESHOLD" default:"0"`
+
+		// HTTP transport; CAFile (PEM) is trusted in addition to the system roots
+		Timeout            time.Duration `envconfig:"ORCHESTRATOR_GIT_TIMEOUT"              default:"60s"`
+		Proxy              string        `envconfig:"ORCHESTRATOR_GIT_PROXY"` // Falls back to HTTP(S)_PROXY when empty
+		CAFile             string        `envconfig:"ORCHESTRATOR_GIT_CA_FILE"`
+		InsecureSkipVerify bool          `envconfig:"ORCHESTRATOR_GIT_INSECURE_SKIP_VERIFY" default:"false"`
+
+		// Retries of idempotent requests for 5xx, 429 and dropped connections; others only when refused. RetryMax 0 disables them
+		RetryMax        int           `envconfig:"ORCHESTRATOR_GIT_RETRY_MAX"         default:"3"`
+		RetryBackoff    time.Duration `envconfig:"ORCHESTRATOR_GIT_RETRY_BACKOFF"     default:"250ms"`
+		RetryMaxBackoff time.Duration `envconfig:"ORCHESTRATOR_GIT_RETRY_MAX_BACKOFF" default:"5s"`
+
+		// Client-side token bucket shared by all calls: requests per second and burst; RateLimit 0 disables it
+		RateLimit float64 `envconfig:"ORCHESTRATOR_GIT_RATE_LIMIT" default:"10"`
+		RateBurst int     `envconfig:"ORCHESTRATOR_GIT_RATE_BURST" default:"20"`
+
+		// Circuit breaker: after BreakerThreshold consecutive failed requests (5xx or dropped connections,
+		// once retries are exhausted) calls fail fast with ErrUnavailable for BreakerCooldown, then a
+		// single request probes for recovery. BreakerThreshold 0 disables it.
+		BreakerThreshold int           `envconfig:"ORCHESTRATOR_GIT_BREAKER_THRESHOLD" default:"5"`
+		BreakerCooldown  time.Duration `envconfig:"ORCHESTRATOR_GIT_BREAKER_COOLDOWN"  default:"30s"`
+
+		// Commit signing: path to an armored OpenPGP or OpenSSH private key. When set, commits are
+		// built and signed client-side and pushed over git; when empty, commits made through the API
+		// are signed only if the server is configured to (Gitea [repository.signing] CRUD_ACTIONS).
+		SigningKey           string `envconfig:"ORCHESTRATOR_GIT_SIGNING_KEY"`
+		SigningKeyPassphrase string `envconfig:"ORCHESTRATOR_GIT_SIGNING_KEY_PASSPHRASE"`
+
+		// DebugHTTP captures every raw request/response pair, secrets redacted, and hands it to DebugHook,
+		// which defaults to an Info log line. Toggle it at runtime with GiteaAdapter.SetHTTPDebug.
+		DebugHTTP bool               `envconfig:"ORCHESTRATOR_GIT_DEBUG_HTTP" default:"false"`
+		DebugHook func(HTTPExchange) `ignored:"true"`
+
+		// Logger receives all adapter logs; nil uses slog.Default(), slog.New(slog.DiscardHandler) silences it
+		Logger *slog.Logger `ignored:"true"`
+
+		// OAuth2 is used instead of Token and TokenFile when OAuth2.ClientID is set
+		OAuth2 OAuth2Config
+
+		// Credentials overrides Token, TokenFile and OAuth2, e.g. OwnerCredentials for one token per organization
+		Credentials CredentialProvider `ignored:"true"`
+
+		// Cache enables read-through caching of GetFile, ListFiles and ListFilesRecursive, e.g. NewLRUCache(1000)
+		Cache Cache `ignored:"true"`
+
+		Limits        Limits
+		Normalization Normalization
+		CommitFormat  CommitFormat
+	}
+
+	// OAuth2Config authenticates with an OAuth2 application instead of a personal access token.
+	// A RefreshToken, e.g. from a one-off authorization code flow, selects the refresh_token grant
+	// (the only one Gitea offers for this); without it the client_credentials grant is used.
+	OAuth2Config struct {
+		ClientID     string   `envconfig:"ORCHESTRATOR_GIT_OAUTH2_CLIENT_ID"`
+		ClientSecret string   `envconfig:"ORCHESTRATOR_GIT_OAUTH2_CLIENT_SECRET"`
+		RefreshToken string   `envconfig:"ORCHESTRATOR_GIT_OAUTH2_REFRESH_TOKEN"`
+		TokenURL     string   `envconfig:"ORCHESTRATOR_GIT_OAUTH2_TOKEN_URL"` // Defaults to {BaseURL}/login/oauth/access_token
+		Scopes       []string `envconfig:"ORCHESTRATOR_GIT_OAUTH2_SCOPES"`
+
+		// OnRefresh receives every new refresh token, so it can be persisted across restarts
+		OnRefresh func(refreshToken string) `ignored:"true"`
+	}
+
+	// Limits bounds what the orchestrator may write, enforced client-side before anything reaches the
+	// server, so a runaway generator cannot push gigabytes into a repository. Zero disables a bound.
+	Limits struct {
+		MaxFileSize      int64 `envconfig:"ORCHESTRATOR_GIT_MAX_FILE_SIZE"      default:"0"` // Bytes per file; LFS uploads are exempt
+		MaxRepoSize      int64 `envconfig:"ORCHESTRATOR_GIT_MAX_REPO_SIZE"      default:"0"` // Bytes of repository storage; costs a request per write on Gitea
+		MaxScaffoldFiles int   `envconfig:"ORCHESTRATOR_GIT_MAX_SCAFFOLD_FILES" default:"0"` // Files per scaffold call
+	}
+
+	// Normalization rewrites the text CommitFile, CommitFiles and scaffolds write, so generated
+	// repositories look the same whichever platform produced them. Binary content, detected like git
+	// does, is left alone, and WriteFile and CommitFileBytes always write verbatim. The zero value keeps content as given.
+	Normalization struct {
+		LineEnding      LineEnding `envconfig:"ORCHESTRATOR_GIT_LINE_ENDING"`                      // LineEndingLF or LineEndingCRLF; empty keeps line endings
+		UTF8            bool       `envconfig:"ORCHESTRATOR_GIT_ENFORCE_UTF8"     default:"false"` // Transcode UTF-16, drop byte order marks and reject other text that is not UTF-8
+		TrailingNewline bool       `envconfig:"ORCHESTRATOR_GIT_TRAILING_NEWLINE" default:"false"` // End non-empty text with a newline
+	}
+
+	// CommitFormat shapes the message of every commit the adapter makes so callers pass plain subjects:
+	// with Type "chore" and Scope "scaffold", "Add service scaffold" becomes "chore(scaffold): Add service
+	// scaffold". Subjects that already have a type are kept. The zero value keeps messages as given.
+	CommitFormat struct {
+		Type       string   `envconfig:"ORCHESTRATOR_GIT_COMMIT_TYPE"`                  // Conventional commits type, e.g. "chore"; WithCommitType overrides it
+		Scope      string   `envconfig:"ORCHESTRATOR_GIT_COMMIT_SCOPE"`                 // Optional scope of Type
+		JobTrailer string   `envconfig:"ORCHESTRATOR_GIT_JOB_TRAILER" default:"Job-Id"` // Trailer key of WithJobID
+		CoAuthors  []string `envconfig:"ORCHESTRATOR_GIT_CO_AUTHORS"`                   // "Name <email>" credited as Co-authored-by on every commit
+	}
+
+	// LimitError is returned, wrapped, when a write would break a Limits bound. It matches ErrLimit
+	// with errors.Is; use errors.As for the details.
+	LimitError struct {
+		Limit string // LimitFileSize, LimitRepoSize or LimitScaffoldFiles
+		Path  string // The offending file, for LimitFileSize
+		Value int64  // Size or count the write would reach
+		Max   int64
+	}
+
+	// LocalGitConfig holds settings for the on-disk go-git adapter
+	LocalGitConfig struct {
+		Root           string `envconfig:"ORCHESTRATOR_GIT_LOCAL_ROOT"  default:"./repos"` // Directory holding one bare repository per project
+		IdName         string `envconfig:"ORCHESTRATOR_GIT_ID_NAME"     default:"ZamineBazi Orchestrator"`
+		IdMail         string `envconfig:"ORCHESTRATOR_GIT_ID_EMAIL"    default:"bot@zaminebazi.com"`
+		Owner          string `envconfig:"ORCHESTRATOR_GIT_OWNER_NAME"  default:"zaminebazi"`
+		Branch         string `envconfig:"ORCHESTRATOR_GIT_BRANCH_NAME" default:"main"`
+		CreateRepoInit bool   `envconfig:"ORCHESTRATOR_GIT_REPO_INIT"   default:"true"`
+
+		// Commit signing with an armored OpenPGP or OpenSSH private key; empty leaves commits unsigned
+		SigningKey           string `envconfig:"ORCHESTRATOR_GIT_SIGNING_KEY"`
+		SigningKeyPassphrase string `envconfig:"ORCHESTRATOR_GIT_SIGNING_KEY_PASSPHRASE"`
+
+		// Logger receives all adapter logs; nil uses slog.Default()
+		Logger *slog.Logger `ignored:"true"`
+
+		Limits        Limits
+		Normalization Normalization
+		CommitFormat  CommitFormat
 	}
 )
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// Headers set by Gitea on every delivery
+	HeaderEvent     = "X-Gitea-Event"
+	HeaderSignature = "X-Gitea-Signature"
+	HeaderDelivery  = "X-Gitea-Delivery"
+
+	EventPush = "push"
+
+	// maxPayloadSize bounds the request body read while verifying a delivery
+	maxPayloadSize = 25 << 20
+)
+
+// Errors returned by the parse helpers; match them with errors.Is
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnexpectedEvent  = errors.New("unexpected webhook event")
+)
+
+type (
+	// PushEvent is the payload Gitea posts for the push event
+	PushEvent struct {
+		Delivery   string     `json:"-"` // X-Gitea-Delivery, unique per delivery, for deduplication
+		Ref        string     `json:"ref"`
+		Before     string     `json:"before"`
+		After      string     `json:"after"`
+		CompareURL string     `json:"compare_url"`
+		Commits    []Commit   `json:"commits"`
+		HeadCommit *Commit    `json:"head_commit"`
+		Repository Repository `json:"repository"`
+		Pusher     User       `json:"pusher"`
+		Sender     User       `json:"sender"`
+	}
+
+	// Commit is a commit listed in a push event
+	Commit struct {
+		ID        string    `json:"id"`
+		Message   string    `json:"message"`
+		URL       string    `json:"url"`
+		Author    Identity  `json:"author"`
+		Committer Identity  `json:"committer"`
+		Timestamp time.Time `json:"timestamp"`
+		Added     []string  `json:"added"`
+		Removed   []string  `json:"removed"`
+		Modified  []string  `json:"modified"`
+	}
+
+	// Identity is the author or committer of a commit
+	Identity struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Username string `json:"username"`
+	}
+
+	// Repository is the repository an event was fired for; Name is the project ID
+	Repository struct {
+		ID            int64  `json:"id"`
+		Name          string `json:"name"`
+		FullName      string `json:"full_name"`
+		Owner         User   `json:"owner"`
+		HTMLURL       string `json:"html_url"`
+		CloneURL      string `json:"clone_url"`
+		DefaultBranch string `json:"default_branch"`
+		Private       bool   `json:"private"`
+	}
+
+	// User is a Gitea account referenced by an event
+	User struct {
+		ID       int64  `json:"id"`
+		Login    string `json:"login"`
+		FullName string `json:"full_name"`
+		Email    string `json:"email"`
+	}
+)
@@ -0,0 +1,94 @@
+// Package webhook parses and verifies Gitea webhook deliveries
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ParsePushEvent verifies and decodes a push delivery. When secret is empty the
+// signature is not checked, which is only safe on a trusted network.
+func ParsePushEvent(r *http.Request, secret string) (*PushEvent, error) {
+	if event := r.Header.Get(HeaderEvent); event != EventPush {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnexpectedEvent, event)
+	}
+
+	body, err := ReadPayload(r, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &PushEvent{Delivery: r.Header.Get(HeaderDelivery)}
+	if err := json.Unmarshal(body, event); err != nil {
+		return nil, fmt.Errorf("failed to decode push event: %w", err)
+	}
+	return event, nil
+}
+
+// ReadPayload reads the request body and verifies its signature against secret,
+// for event types without a dedicated parser
+func ReadPayload(r *http.Request, secret string) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook payload: %w", err)
+	}
+	if len(body) > maxPayloadSize {
+		return nil, fmt.Errorf("failed to read webhook payload: larger than %d bytes", maxPayloadSize)
+	}
+
+	if secret != "" {
+		if err := VerifySignature(body, r.Header.Get(HeaderSignature), secret); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// VerifySignature checks the hex HMAC-SHA256 signature Gitea computes over the raw body
+func VerifySignature(body []byte, signature, secret string) error {
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(got) == 0 {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Branch returns the pushed branch name, or "" when the push was not to a branch
+func (e *PushEvent) Branch() string {
+	if !strings.HasPrefix(e.Ref, "refs/heads/") {
+		return ""
+	}
+	return strings.TrimPrefix(e.Ref, "refs/heads/")
+}
+
+// Deleted reports whether the push deleted the ref
+func (e *PushEvent) Deleted() bool {
+	return strings.Trim(e.After, "0") == ""
+}
+
+// ProjectID parses the project ID the adapters put at the end of the repository name
+func (e *PushEvent) ProjectID() (uuid.UUID, error) {
+	name := e.Repository.Name
+	if len(name) > 36 {
+		name = name[len(name)-36:]
+	}
+	id, err := uuid.Parse(name)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to parse project ID '%s': %w", e.Repository.Name, err)
+	}
+	return id, nil
+}